@@ -0,0 +1,96 @@
+package k256
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// frameHeaderSize is the fixed binary frame header: 1-byte MessageType + 4-byte LE length.
+const frameHeaderSize = 5
+
+// Encoder writes type-tagged, length-prefixed binary frames to an underlying writer.
+// It is for anything that needs to persist a stream of K256 messages outside of a
+// single WebSocket frame (e.g. a recorder). A binary WebSocket frame itself carries
+// no length prefix: it is the 1-byte MessageType followed directly by the payload.
+type Encoder struct {
+	w io.Writer
+}
+
+// NewEncoder returns an Encoder that writes frames to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode writes one frame: msgType (1 byte), len(payload) (4 bytes LE), then payload.
+func (e *Encoder) Encode(msgType MessageType, payload []byte) error {
+	header := make([]byte, frameHeaderSize)
+	header[0] = byte(msgType)
+	binary.LittleEndian.PutUint32(header[1:], uint32(len(payload)))
+
+	if _, err := e.w.Write(header); err != nil {
+		return fmt.Errorf("write frame header: %w", err)
+	}
+	if len(payload) == 0 {
+		return nil
+	}
+	if _, err := e.w.Write(payload); err != nil {
+		return fmt.Errorf("write frame payload: %w", err)
+	}
+	return nil
+}
+
+// Decoder reads type-tagged, length-prefixed binary frames from an underlying reader.
+type Decoder struct {
+	r io.Reader
+}
+
+// NewDecoder returns a Decoder that reads frames from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r}
+}
+
+// Decode reads the next frame and returns its MessageType and raw payload.
+// It returns io.EOF (unwrapped) when the reader is exhausted at a frame boundary.
+func (d *Decoder) Decode() (MessageType, []byte, error) {
+	header := make([]byte, frameHeaderSize)
+	if _, err := io.ReadFull(d.r, header); err != nil {
+		return 0, nil, err
+	}
+
+	msgType := MessageType(header[0])
+	length := binary.LittleEndian.Uint32(header[1:])
+
+	payload := make([]byte, length)
+	if length > 0 {
+		if _, err := io.ReadFull(d.r, payload); err != nil {
+			return 0, nil, fmt.Errorf("read frame payload: %w", err)
+		}
+	}
+
+	return msgType, payload, nil
+}
+
+// DecodeMessage decodes a frame payload into its typed message based on msgType.
+// The concrete return type depends on msgType: *PoolUpdate, []*PoolUpdate, *FeeMarket,
+// *Blockhash, *Quote, or *Heartbeat. MessageTypeError yields a nil value and a non-nil error.
+func DecodeMessage(msgType MessageType, payload []byte) (interface{}, error) {
+	switch msgType {
+	case MessageTypePoolUpdate:
+		return DecodePoolUpdate(payload)
+	case MessageTypePoolUpdateBatch:
+		return DecodePoolUpdateBatch(payload)
+	case MessageTypePriorityFees:
+		return DecodeFeeMarket(payload)
+	case MessageTypeBlockhash:
+		return DecodeBlockhash(payload)
+	case MessageTypeQuote:
+		return DecodeQuote(payload)
+	case MessageTypeHeartbeat:
+		return DecodeHeartbeat(payload)
+	case MessageTypeError:
+		return nil, fmt.Errorf("server error: %s", string(payload))
+	default:
+		return nil, fmt.Errorf("unknown message type: 0x%02X", byte(msgType))
+	}
+}
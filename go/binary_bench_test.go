@@ -0,0 +1,77 @@
+package k256
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+)
+
+func heartbeatBinaryPayload() []byte {
+	payload := make([]byte, 36)
+	binary.LittleEndian.PutUint64(payload[0:], 1700000000000)
+	binary.LittleEndian.PutUint64(payload[8:], 3600)
+	binary.LittleEndian.PutUint64(payload[16:], 1_000_000)
+	binary.LittleEndian.PutUint64(payload[24:], 500_000)
+	binary.LittleEndian.PutUint32(payload[32:], 12)
+	return payload
+}
+
+func heartbeatJSONPayload(tb testing.TB) []byte {
+	data, err := json.Marshal(Heartbeat{
+		TimestampMs:      1700000000000,
+		UptimeSeconds:    3600,
+		MessagesReceived: 1_000_000,
+		MessagesSent:     500_000,
+		Subscriptions:    12,
+	})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return data
+}
+
+// BenchmarkDecodeHeartbeatBinary measures DecodeMessage's binary path for a
+// Heartbeat frame.
+func BenchmarkDecodeHeartbeatBinary(b *testing.B) {
+	payload := heartbeatBinaryPayload()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := DecodeMessage(MessageTypeHeartbeat, payload); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkDecodeHeartbeatJSON measures decoding the same heartbeat from its
+// JSON representation, as a baseline for the binary path above.
+func BenchmarkDecodeHeartbeatJSON(b *testing.B) {
+	payload := heartbeatJSONPayload(b)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var hb Heartbeat
+		if err := json.Unmarshal(payload, &hb); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkEncodeDecodeFrame measures round-tripping a frame through
+// Encoder/Decoder, the type-tagged length-prefixed framing used to persist
+// messages outside of a single WebSocket frame (e.g. replay.Recorder).
+func BenchmarkEncodeDecodeFrame(b *testing.B) {
+	payload := heartbeatBinaryPayload()
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		if err := enc.Encode(MessageTypeHeartbeat, payload); err != nil {
+			b.Fatal(err)
+		}
+		dec := NewDecoder(&buf)
+		if _, _, err := dec.Decode(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
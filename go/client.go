@@ -1,6 +1,7 @@
 package k256
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -26,6 +27,16 @@ type Config struct {
 	ReconnectDelayMax time.Duration
 	// PingInterval is the ping interval (0 to disable)
 	PingInterval time.Duration
+	// Format selects the wire format: "json" (default) or "binary".
+	Format string
+	// SendQueueSize is the capacity of the outbound send queue backing
+	// CallContext and the subscribe/unsubscribe control frames. Defaults to
+	// DefaultSendQueueSize.
+	SendQueueSize int
+	// Dialer establishes the connection. Defaults to a Dialer backed by
+	// websocket.DefaultDialer; override with a fake (e.g. replay.FileDialer)
+	// to drive the client from a recorded session in tests.
+	Dialer Dialer
 }
 
 // DefaultConfig returns a Config with default values.
@@ -36,6 +47,8 @@ func DefaultConfig() Config {
 		ReconnectDelayInitial: time.Second,
 		ReconnectDelayMax:     60 * time.Second,
 		PingInterval:          30 * time.Second,
+		Format:                "json",
+		SendQueueSize:         DefaultSendQueueSize,
 	}
 }
 
@@ -52,21 +65,30 @@ type SubscribeRequest struct {
 // WebSocketClient is the K256 WebSocket client for real-time Solana liquidity data.
 type WebSocketClient struct {
 	config Config
-	conn   *websocket.Conn
+	conn   WSConn
 	mu     sync.RWMutex
 
-	running          bool
-	reconnectDelay   time.Duration
-	lastSubscription *SubscribeRequest
+	running        bool
+	reconnectDelay time.Duration
+	subs           *subscriptionManager
 
-	onPoolUpdate func(*PoolUpdate)
-	onFeeMarket  func(*FeeMarket)
-	onBlockhash  func(*Blockhash)
-	onQuote         func(*Quote)
-	onHeartbeat     func(*Heartbeat)
-	onError         func(error)
-	onConnected     func()
-	onDisconnected  func()
+	rpc         rpcState
+	sendQueue   chan sendFrame
+	writePumpOn bool
+	done        chan struct{}
+
+	poolBooks map[string]PoolUpdateSink
+
+	flow *flowController
+
+	onPoolUpdate   func(*PoolUpdate)
+	onFeeMarket    func(*FeeMarket)
+	onBlockhash    func(*Blockhash)
+	onQuote        func(*Quote)
+	onHeartbeat    func(*Heartbeat)
+	onError        func(error)
+	onConnected    func()
+	onDisconnected func()
 }
 
 // NewWebSocket creates a new WebSocket client with the given configuration.
@@ -80,13 +102,50 @@ func NewWebSocket(config Config) *WebSocketClient {
 	if config.ReconnectDelayMax == 0 {
 		config.ReconnectDelayMax = 60 * time.Second
 	}
+	if config.Format == "" {
+		config.Format = "json"
+	}
+	if config.SendQueueSize == 0 {
+		config.SendQueueSize = DefaultSendQueueSize
+	}
+	if config.Dialer == nil {
+		config.Dialer = defaultDialer
+	}
 
 	return &WebSocketClient{
 		config:         config,
 		reconnectDelay: config.ReconnectDelayInitial,
+		subs:           newSubscriptionManager(),
+		sendQueue:      make(chan sendFrame, config.SendQueueSize),
+		done:           make(chan struct{}),
+		poolBooks:      make(map[string]PoolUpdateSink),
+		flow:           newFlowController(),
 	}
 }
 
+// SetFlowConfig configures the bounded buffer backing callbacks for
+// msgType: its capacity, delivery rate and drop policy. Call before Connect
+// to take effect from the first message of that type.
+func (c *WebSocketClient) SetFlowConfig(msgType MessageType, config FlowConfig) {
+	c.flow.setConfig(msgType, config)
+}
+
+// SetPriority marks msgType as exempt from its DropPolicy once priority
+// reaches CriticalPriority, so a high-volume type like PoolUpdate can never
+// cause a critical type like Blockhash or FeeMarket to be dropped for being
+// behind in the queue. Only the threshold matters: values below
+// CriticalPriority have no effect, and any value at or above it behaves the
+// same as CriticalPriority.
+func (c *WebSocketClient) SetPriority(msgType MessageType, priority int) {
+	c.flow.setPriority(msgType, priority)
+}
+
+// Stats returns the live drop/coalesce/queue-depth counters for msgType's
+// flow-controlled buffer.
+func (c *WebSocketClient) Stats(msgType MessageType) FlowStats {
+	return c.flow.stats(msgType)
+}
+
 // OnPoolUpdate registers a callback for pool updates.
 func (c *WebSocketClient) OnPoolUpdate(callback func(*PoolUpdate)) {
 	c.onPoolUpdate = callback
@@ -137,6 +196,22 @@ func (c *WebSocketClient) IsConnected() bool {
 // Connect establishes a connection to the K256 WebSocket.
 func (c *WebSocketClient) Connect() error {
 	c.running = true
+
+	c.mu.Lock()
+	if !c.writePumpOn {
+		c.writePumpOn = true
+		c.done = make(chan struct{})
+		go c.writePump()
+		c.flow.start(map[MessageType]func(interface{}){
+			MessageTypePoolUpdate:   c.deliverPoolUpdate,
+			MessageTypePriorityFees: c.deliverFeeMarket,
+			MessageTypeBlockhash:    c.deliverBlockhash,
+			MessageTypeQuote:        c.deliverQuote,
+			MessageTypeHeartbeat:    c.deliverHeartbeat,
+		})
+	}
+	c.mu.Unlock()
+
 	return c.connectLoop()
 }
 
@@ -191,7 +266,7 @@ func (c *WebSocketClient) connect() error {
 	q.Set("apiKey", c.config.APIKey)
 	u.RawQuery = q.Encode()
 
-	conn, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	conn, _, err := c.config.Dialer.Dial(u.String(), nil)
 	if err != nil {
 		return fmt.Errorf("dial error: %w", err)
 	}
@@ -206,9 +281,12 @@ func (c *WebSocketClient) connect() error {
 		c.onConnected()
 	}
 
-	// Resubscribe if we had a previous subscription
-	if c.lastSubscription != nil {
-		if err := c.sendSubscribe(c.lastSubscription); err != nil {
+	// Replay every active subscription as its own frame, rather than
+	// collapsing them into one combined request.
+	for _, sub := range c.subs.snapshot() {
+		frame := sub.frame
+		frame.Type = "subscribe"
+		if err := c.sendSubscribe(&frame); err != nil {
 			return err
 		}
 	}
@@ -250,72 +328,47 @@ func (c *WebSocketClient) handleBinaryMessage(data []byte) {
 	msgType := MessageType(data[0])
 	payload := data[1:]
 
-	switch msgType {
-	case MessageTypePoolUpdate:
-		if c.onPoolUpdate != nil {
-			update, err := DecodePoolUpdate(payload)
-			if err != nil {
-				log.Printf("Error decoding pool update: %v", err)
-				return
-			}
-			c.onPoolUpdate(update)
-		}
-
-	case MessageTypePoolUpdateBatch:
-		if c.onPoolUpdate != nil {
-			updates, err := DecodePoolUpdateBatch(payload)
-			if err != nil {
-				log.Printf("Error decoding pool update batch: %v", err)
-				return
-			}
-			for _, update := range updates {
-				c.onPoolUpdate(update)
-			}
-		}
-
-	case MessageTypePriorityFees:
-		if c.onFeeMarket != nil {
-			fees, err := DecodeFeeMarket(payload)
-			if err != nil {
-				log.Printf("Error decoding fee market: %v", err)
-				return
-			}
-			c.onFeeMarket(fees)
-		}
-
-	case MessageTypeBlockhash:
-		if c.onBlockhash != nil {
-			bh, err := DecodeBlockhash(payload)
-			if err != nil {
-				log.Printf("Error decoding blockhash: %v", err)
-				return
-			}
-			c.onBlockhash(bh)
-		}
+	if msgType == MessageTypePong {
+		// Pong response - no action needed, keepalive handled
+		return
+	}
 
-	case MessageTypeQuote:
-		if c.onQuote != nil {
-			quote, err := DecodeQuote(payload)
-			if err != nil {
-				log.Printf("Error decoding quote: %v", err)
-				return
+	decoded, err := DecodeMessage(msgType, payload)
+	if err != nil {
+		if msgType == MessageTypeError {
+			log.Printf("Server error: %v", err)
+			if c.onError != nil {
+				c.onError(err)
 			}
-			c.onQuote(quote)
+			return
 		}
+		log.Printf("Error decoding message type 0x%02X: %v", byte(msgType), err)
+		return
+	}
 
-	case MessageTypePong:
-		// Pong response - no action needed, keepalive handled
-
-	case MessageTypeError:
-		errMsg := string(payload)
-		log.Printf("Server error: %s", errMsg)
-		if c.onError != nil {
-			c.onError(fmt.Errorf("server error: %s", errMsg))
+	switch v := decoded.(type) {
+	case *PoolUpdate:
+		c.dispatchPoolUpdate(v)
+	case []*PoolUpdate:
+		for _, update := range v {
+			c.dispatchPoolUpdate(update)
 		}
+	case *FeeMarket:
+		c.dispatchFeeMarket(v)
+	case *Blockhash:
+		c.dispatchBlockhash(v)
+	case *Quote:
+		c.dispatchQuote(v)
+	case *Heartbeat:
+		c.dispatchHeartbeat(v)
 	}
 }
 
 func (c *WebSocketClient) handleTextMessage(data []byte) {
+	if c.handleRPCResponse(data) {
+		return
+	}
+
 	var msg map[string]interface{}
 	if err := json.Unmarshal(data, &msg); err != nil {
 		log.Printf("Error parsing JSON: %v", err)
@@ -355,12 +408,140 @@ func (c *WebSocketClient) handleTextMessage(data []byte) {
 	}
 }
 
-func (c *WebSocketClient) sendSubscribe(request *SubscribeRequest) error {
+// PoolUpdateSink receives every PoolUpdate accepted for a registered pool
+// address. *depth.PoolBook implements this, so RegisterPoolBook gives
+// callers a stable "latest state per pool" view instead of raw deltas.
+type PoolUpdateSink interface {
+	Add(update *PoolUpdate)
+}
+
+// RegisterPoolBook feeds every future PoolUpdate for addr to sink, in
+// addition to the existing OnPoolUpdate and SubscribePool/SubscribePair
+// callbacks. Registering again for the same address replaces the sink.
+func (c *WebSocketClient) RegisterPoolBook(addr string, sink PoolUpdateSink) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.poolBooks[addr] = sink
+}
+
+// UnregisterPoolBook stops feeding PoolUpdates for addr to a previously
+// registered sink.
+func (c *WebSocketClient) UnregisterPoolBook(addr string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.poolBooks, addr)
+}
+
+// dispatchPoolUpdate hands update to the MessageTypePoolUpdate flow buffer,
+// which delivers it via deliverPoolUpdate on its own goroutine once paced
+// and past any drop/coalesce policy. This keeps a slow OnPoolUpdate (by far
+// the highest-volume callback) from blocking delivery of other types.
+func (c *WebSocketClient) dispatchPoolUpdate(update *PoolUpdate) {
+	c.flow.push(MessageTypePoolUpdate, flowItem{
+		payload:      update,
+		poolAddress:  update.PoolAddress,
+		writeVersion: update.WriteVersion,
+	})
+}
+
+// deliverPoolUpdate invokes the global OnPoolUpdate callback (if any) plus
+// every keyed subscription callback that matches update: SubscribeAllPools,
+// SubscribePool(update.PoolAddress) and SubscribePair for update's mints.
+// It also feeds update to a PoolBook registered for its address, if any.
+func (c *WebSocketClient) deliverPoolUpdate(payload interface{}) {
+	update := payload.(*PoolUpdate)
+
+	if c.onPoolUpdate != nil {
+		c.onPoolUpdate(update)
+	}
+
+	for _, cb := range c.subs.callbacksForKey(allPoolsKey) {
+		cb.(func(*PoolUpdate))(update)
+	}
+	for _, cb := range c.subs.callbacksForKey(poolKey(update.PoolAddress)) {
+		cb.(func(*PoolUpdate))(update)
+	}
+	if len(update.TokenMints) >= 2 {
+		for _, cb := range c.subs.callbacksForKey(pairKey(update.TokenMints[0], update.TokenMints[1])) {
+			cb.(func(*PoolUpdate))(update)
+		}
+	}
+
 	c.mu.RLock()
-	conn := c.conn
+	sink := c.poolBooks[update.PoolAddress]
 	c.mu.RUnlock()
+	if sink != nil {
+		sink.Add(update)
+	}
+}
+
+// dispatchFeeMarket hands fees to the MessageTypePriorityFees flow buffer.
+func (c *WebSocketClient) dispatchFeeMarket(fees *FeeMarket) {
+	c.flow.push(MessageTypePriorityFees, flowItem{payload: fees})
+}
+
+// deliverFeeMarket invokes the global OnFeeMarket callback plus every
+// SubscribeFees callback.
+func (c *WebSocketClient) deliverFeeMarket(payload interface{}) {
+	fees := payload.(*FeeMarket)
+	if c.onFeeMarket != nil {
+		c.onFeeMarket(fees)
+	}
+	for _, cb := range c.subs.callbacksForKey(feesKey) {
+		cb.(func(*FeeMarket))(fees)
+	}
+}
+
+// dispatchBlockhash hands bh to the MessageTypeBlockhash flow buffer.
+func (c *WebSocketClient) dispatchBlockhash(bh *Blockhash) {
+	c.flow.push(MessageTypeBlockhash, flowItem{payload: bh})
+}
+
+// deliverBlockhash invokes the global OnBlockhash callback plus every
+// SubscribeBlockhash callback.
+func (c *WebSocketClient) deliverBlockhash(payload interface{}) {
+	bh := payload.(*Blockhash)
+	if c.onBlockhash != nil {
+		c.onBlockhash(bh)
+	}
+	for _, cb := range c.subs.callbacksForKey(blockhashKey) {
+		cb.(func(*Blockhash))(bh)
+	}
+}
+
+// dispatchQuote hands quote to the MessageTypeQuote flow buffer.
+func (c *WebSocketClient) dispatchQuote(quote *Quote) {
+	c.flow.push(MessageTypeQuote, flowItem{payload: quote})
+}
+
+// deliverQuote invokes the global OnQuote callback plus every
+// SubscribeQuote callback registered for quote's input/output mint pair.
+func (c *WebSocketClient) deliverQuote(payload interface{}) {
+	quote := payload.(*Quote)
+	if c.onQuote != nil {
+		c.onQuote(quote)
+	}
+	for _, cb := range c.subs.callbacksForKey(quoteKey(quote.InputMint, quote.OutputMint)) {
+		cb.(func(*Quote))(quote)
+	}
+}
+
+// dispatchHeartbeat hands hb to the MessageTypeHeartbeat flow buffer. Given
+// its own buffer, a heartbeat is never starved by a backlog of pool updates
+// sharing the connection.
+func (c *WebSocketClient) dispatchHeartbeat(hb *Heartbeat) {
+	c.flow.push(MessageTypeHeartbeat, flowItem{payload: hb})
+}
+
+// deliverHeartbeat invokes the global OnHeartbeat callback, if any.
+func (c *WebSocketClient) deliverHeartbeat(payload interface{}) {
+	if c.onHeartbeat != nil {
+		c.onHeartbeat(payload.(*Heartbeat))
+	}
+}
 
-	if conn == nil {
+func (c *WebSocketClient) sendSubscribe(request *SubscribeRequest) error {
+	if !c.IsConnected() {
 		return fmt.Errorf("not connected")
 	}
 
@@ -369,33 +550,87 @@ func (c *WebSocketClient) sendSubscribe(request *SubscribeRequest) error {
 		return err
 	}
 
-	return conn.WriteMessage(websocket.TextMessage, data)
+	wsMsgType := websocket.TextMessage
+	if request.Format == "binary" {
+		wsMsgType = websocket.BinaryMessage
+	}
+
+	return c.enqueueSend(context.Background(), wsMsgType, data)
 }
 
-// Subscribe subscribes to the specified channels.
-func (c *WebSocketClient) Subscribe(request SubscribeRequest) error {
-	request.Type = "subscribe"
-	c.lastSubscription = &request
+// addSubscription registers callback under key in the subscription manager
+// and, if it's the first subscription for that key, sends frame to the
+// gateway. Later callers sharing the same key piggyback on the subscription
+// already established there instead of sending a duplicate frame.
+func (c *WebSocketClient) addSubscription(key string, frame SubscribeRequest, callback interface{}) (SubID, error) {
+	frame.Type = "subscribe"
+	if frame.Format == "" {
+		frame.Format = c.config.Format
+	}
 
-	if c.IsConnected() {
-		return c.sendSubscribe(&request)
+	sub, first := c.subs.add(key, frame, callback)
+	if first && c.IsConnected() {
+		if err := c.sendSubscribe(&frame); err != nil {
+			c.subs.remove(sub.id)
+			return 0, err
+		}
 	}
-	return nil
+	return sub.id, nil
+}
+
+// SubscribeAllPools subscribes to the unfiltered pool-update stream: every
+// pool, not just ones matching a specific address or token pair.
+func (c *WebSocketClient) SubscribeAllPools(callback func(*PoolUpdate)) (SubID, error) {
+	return c.addSubscription(allPoolsKey, SubscribeRequest{Channels: []string{"pools"}}, callback)
 }
 
-// Unsubscribe unsubscribes from all channels.
-func (c *WebSocketClient) Unsubscribe() error {
-	c.lastSubscription = nil
+// SubscribePool subscribes to updates for a single pool address. Several
+// independent SubscribePool/SubscribePair/etc. calls can be active at once
+// without overwriting each other, unlike the old Subscribe(SubscribeRequest)
+// "last wins" behavior.
+func (c *WebSocketClient) SubscribePool(addr string, callback func(*PoolUpdate)) (SubID, error) {
+	return c.addSubscription(poolKey(addr), SubscribeRequest{Pools: []string{addr}}, callback)
+}
 
-	c.mu.RLock()
-	conn := c.conn
-	c.mu.RUnlock()
+// SubscribePair subscribes to pool updates for any pool trading mintA
+// against mintB, in either order.
+func (c *WebSocketClient) SubscribePair(mintA, mintB string, callback func(*PoolUpdate)) (SubID, error) {
+	frame := SubscribeRequest{TokenPairs: [][]string{{mintA, mintB}}}
+	return c.addSubscription(pairKey(mintA, mintB), frame, callback)
+}
+
+// SubscribeQuote subscribes to the streaming quote channel for swaps from
+// inputMint to outputMint.
+func (c *WebSocketClient) SubscribeQuote(inputMint, outputMint string, callback func(*Quote)) (SubID, error) {
+	frame := SubscribeRequest{Channels: []string{"quotes"}, TokenPairs: [][]string{{inputMint, outputMint}}}
+	return c.addSubscription(quoteKey(inputMint, outputMint), frame, callback)
+}
+
+// SubscribeFees subscribes to the priority_fees channel.
+func (c *WebSocketClient) SubscribeFees(callback func(*FeeMarket)) (SubID, error) {
+	return c.addSubscription(feesKey, SubscribeRequest{Channels: []string{"priority_fees"}}, callback)
+}
 
-	if conn == nil {
+// SubscribeBlockhash subscribes to the blockhash channel.
+func (c *WebSocketClient) SubscribeBlockhash(callback func(*Blockhash)) (SubID, error) {
+	return c.addSubscription(blockhashKey, SubscribeRequest{Channels: []string{"blockhash"}}, callback)
+}
+
+// Unsubscribe removes the subscription identified by id. If id was the last
+// subscription sharing its key, an unsubscribe frame for that key is sent to
+// the gateway; otherwise the server-side subscription stays up for the
+// remaining callers.
+func (c *WebSocketClient) Unsubscribe(id SubID) error {
+	frame, last, found := c.subs.remove(id)
+	if !found {
+		return fmt.Errorf("unknown subscription id %d", id)
+	}
+	if !last || !c.IsConnected() {
 		return nil
 	}
 
-	return conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"unsubscribe"}`))
+	frame.Type = "unsubscribe"
+	return c.sendSubscribe(&frame)
 }
 
 // Close closes the WebSocket connection.
@@ -405,6 +640,11 @@ func (c *WebSocketClient) Close() error {
 	c.mu.Lock()
 	conn := c.conn
 	c.conn = nil
+	if c.writePumpOn {
+		c.writePumpOn = false
+		close(c.done)
+		c.flow.close()
+	}
 	c.mu.Unlock()
 
 	if conn != nil {
@@ -0,0 +1,55 @@
+// k256-tap streams the live leader-schedule WebSocket and records it to a
+// newline-delimited JSON corpus file for offline replay.
+//
+// Usage:
+//
+//	K256_API_KEY=your-key go run ./cmd/k256-tap -out corpus.ndjson
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	k256 "github.com/k256-xyz/k256-sdks/go"
+)
+
+func main() {
+	out := flag.String("out", "k256-capture.ndjson", "path to write the recorded corpus to")
+	flag.Parse()
+
+	apiKey := os.Getenv("K256_API_KEY")
+	if apiKey == "" {
+		log.Fatal("K256_API_KEY environment variable is required")
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		log.Fatalf("create capture file: %v", err)
+	}
+	defer f.Close()
+
+	config := k256.DefaultLeaderConfig()
+	config.APIKey = apiKey
+
+	client := k256.NewLeaderClient(config, func(msg k256.LeaderMessage) {
+		fmt.Printf("[%s] %s\n", msg.Type, msg.Kind)
+	})
+	client.SetRecorder(k256.NewRecorder(f))
+
+	if err := client.Connect(); err != nil {
+		log.Fatalf("connect: %v", err)
+	}
+
+	fmt.Printf("Recording to %s, press Ctrl+C to stop...\n", *out)
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	<-sigChan
+
+	fmt.Println("\nStopping capture...")
+	client.Close()
+}
@@ -297,6 +297,21 @@ func DecodeBlockhash(data []byte) (*Blockhash, error) {
 	}, nil
 }
 
+// DecodeHeartbeat decodes a heartbeat from bincode format (fixed 36 bytes).
+func DecodeHeartbeat(data []byte) (*Heartbeat, error) {
+	if len(data) < 36 {
+		return nil, fmt.Errorf("payload too short: %d < 36", len(data))
+	}
+
+	return &Heartbeat{
+		TimestampMs:      binary.LittleEndian.Uint64(data[0:]),
+		UptimeSeconds:    binary.LittleEndian.Uint64(data[8:]),
+		MessagesReceived: binary.LittleEndian.Uint64(data[16:]),
+		MessagesSent:     binary.LittleEndian.Uint64(data[24:]),
+		Subscriptions:    binary.LittleEndian.Uint32(data[32:]),
+	}, nil
+}
+
 // DecodeQuote decodes a quote message (JSON format).
 func DecodeQuote(data []byte) (*Quote, error) {
 	var quote Quote
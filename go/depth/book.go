@@ -0,0 +1,192 @@
+// Package depth reassembles K256's PoolUpdate deltas into an ordered,
+// gap-checked "latest state per pool" view, modeled after bbgo's
+// depth.Buffer.
+package depth
+
+import (
+	"sync"
+
+	k256 "github.com/k256-xyz/k256-sdks/go"
+)
+
+// maxPending bounds how many out-of-order updates PoolBook buffers while
+// waiting for the update that fills the gap before reporting it via OnGap.
+const maxPending = 64
+
+// PoolSnapshot is the current reconciled state of a single pool, maintained
+// in place by PoolBook as updates arrive in order.
+type PoolSnapshot struct {
+	PoolAddress   string
+	Slot          uint64
+	WriteVersion  uint64
+	Sequence      uint64
+	ProtocolName  string
+	TokenMints    []string
+	TokenBalances []uint64
+	TokenDecimals []int32
+	BestBid       *k256.OrderLevel
+	BestAsk       *k256.OrderLevel
+}
+
+// PoolBook reassembles k256.PoolUpdate messages for a single pool address
+// into an ordered stream. It buffers updates that arrive out of order,
+// drops ones already superseded, and reports a gap when the buffer can't
+// wait any longer for the update that fills it.
+type PoolBook struct {
+	mu sync.Mutex
+
+	poolAddress string
+
+	have     bool
+	snapshot PoolSnapshot
+	pending  map[uint64]*k256.PoolUpdate // Sequence -> update, awaiting the gap to fill
+
+	// gapReported tracks whether OnGap has already fired for the gap
+	// currently being waited on, so a sustained gap (caller slow to call
+	// Reset) notifies once instead of on every subsequent Add.
+	gapReported bool
+
+	onReady func(*PoolSnapshot)
+	onGap   func(firstMissingSeq, lastSeq uint64)
+}
+
+// NewPoolBook returns a PoolBook that reassembles updates for poolAddress.
+func NewPoolBook(poolAddress string) *PoolBook {
+	return &PoolBook{
+		poolAddress: poolAddress,
+		pending:     make(map[uint64]*k256.PoolUpdate),
+	}
+}
+
+// PoolAddress returns the address this book reassembles updates for.
+func (b *PoolBook) PoolAddress() string {
+	return b.poolAddress
+}
+
+// OnReady registers the callback invoked with the reconciled snapshot every
+// time it advances in order. Only one callback may be registered.
+func (b *PoolBook) OnReady(callback func(*PoolSnapshot)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onReady = callback
+}
+
+// OnGap registers the callback invoked when PoolBook gives up waiting for a
+// missing range of sequence numbers, so the caller can trigger a REST
+// resync and feed the result back in via Reset. Only one callback may be
+// registered.
+func (b *PoolBook) OnGap(callback func(firstMissingSeq, lastSeq uint64)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onGap = callback
+}
+
+// Add feeds a PoolUpdate into the book. Updates for other pool addresses
+// are ignored, so callers can wire every PoolUpdate from WebSocketClient
+// straight through without pre-filtering.
+func (b *PoolBook) Add(update *k256.PoolUpdate) {
+	if update == nil || update.PoolAddress != b.poolAddress {
+		return
+	}
+
+	b.mu.Lock()
+	ready, gapFirst, gapLast, hasGap := b.addLocked(update)
+	onReady, onGap := b.onReady, b.onGap
+	b.mu.Unlock()
+
+	if onReady != nil {
+		for _, snap := range ready {
+			onReady(snap)
+		}
+	}
+	if hasGap && onGap != nil {
+		onGap(gapFirst, gapLast)
+	}
+}
+
+// Reset re-baselines the book to update, discarding any buffered
+// out-of-order updates. Call this with the result of a REST resync
+// triggered by OnGap.
+func (b *PoolBook) Reset(update *k256.PoolUpdate) {
+	b.mu.Lock()
+	b.pending = make(map[uint64]*k256.PoolUpdate)
+	b.gapReported = false
+	snap := b.applyLocked(update)
+	onReady := b.onReady
+	b.mu.Unlock()
+
+	if onReady != nil {
+		onReady(snap)
+	}
+}
+
+// Snapshot returns a copy of the most recently reconciled state, and
+// whether any update has been applied yet.
+func (b *PoolBook) Snapshot() (PoolSnapshot, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.snapshot, b.have
+}
+
+func (b *PoolBook) addLocked(update *k256.PoolUpdate) (ready []*PoolSnapshot, gapFirst, gapLast uint64, hasGap bool) {
+	if b.have {
+		if update.Sequence <= b.snapshot.Sequence {
+			return nil, 0, 0, false // already applied or a stale duplicate
+		}
+		if update.WriteVersion < b.snapshot.WriteVersion {
+			return nil, 0, 0, false // older than what's already been shown
+		}
+	}
+
+	expected := b.snapshot.Sequence + 1
+	if !b.have || update.Sequence == expected {
+		b.gapReported = false
+		ready = append(ready, b.applyLocked(update))
+		ready = append(ready, b.drainPendingLocked()...)
+		return ready, 0, 0, false
+	}
+
+	if _, buffered := b.pending[update.Sequence]; !buffered && len(b.pending) >= maxPending {
+		// Already full: drop the update rather than growing pending further,
+		// and only notify the first time this gap crosses maxPending so a
+		// caller slow to call Reset isn't flooded with repeat OnGap calls.
+		if !b.gapReported {
+			b.gapReported = true
+			return nil, expected, update.Sequence, true
+		}
+		return nil, 0, 0, false
+	}
+
+	b.pending[update.Sequence] = update
+	return nil, 0, 0, false
+}
+
+func (b *PoolBook) applyLocked(update *k256.PoolUpdate) *PoolSnapshot {
+	b.have = true
+	b.snapshot = PoolSnapshot{
+		PoolAddress:   update.PoolAddress,
+		Slot:          update.Slot,
+		WriteVersion:  update.WriteVersion,
+		Sequence:      update.Sequence,
+		ProtocolName:  update.ProtocolName,
+		TokenMints:    update.TokenMints,
+		TokenBalances: update.TokenBalances,
+		TokenDecimals: update.TokenDecimals,
+		BestBid:       update.BestBid,
+		BestAsk:       update.BestAsk,
+	}
+	snap := b.snapshot
+	return &snap
+}
+
+func (b *PoolBook) drainPendingLocked() []*PoolSnapshot {
+	var ready []*PoolSnapshot
+	for {
+		next, ok := b.pending[b.snapshot.Sequence+1]
+		if !ok {
+			return ready
+		}
+		delete(b.pending, b.snapshot.Sequence+1)
+		ready = append(ready, b.applyLocked(next))
+	}
+}
@@ -0,0 +1,43 @@
+package k256
+
+import (
+	"net/http"
+
+	"github.com/gorilla/websocket"
+)
+
+// WSConn is the subset of *websocket.Conn that WebSocketClient depends on.
+// It exists so Config.Dialer can be swapped for a fake in tests (see the
+// k256/replay subpackage), without WebSocketClient itself depending on
+// anything beyond read/write/close.
+type WSConn interface {
+	ReadMessage() (messageType int, p []byte, err error)
+	WriteMessage(messageType int, data []byte) error
+	Close() error
+}
+
+// Dialer establishes the connection WebSocketClient reads and writes
+// frames over. The zero Config uses gorillaDialer, wrapping
+// websocket.DefaultDialer; tests substitute a fake (e.g. replay.FileDialer)
+// to drive the client from a recorded session instead of the live gateway.
+type Dialer interface {
+	Dial(urlStr string, requestHeader http.Header) (WSConn, *http.Response, error)
+}
+
+// gorillaDialer adapts *websocket.Dialer to the Dialer interface: gorilla's
+// Dial returns a concrete *websocket.Conn, which Go won't implicitly widen
+// to WSConn across an interface method signature, so the call is wrapped
+// here instead.
+type gorillaDialer struct {
+	*websocket.Dialer
+}
+
+func (d gorillaDialer) Dial(urlStr string, requestHeader http.Header) (WSConn, *http.Response, error) {
+	conn, resp, err := d.Dialer.Dial(urlStr, requestHeader)
+	if err != nil {
+		return nil, resp, err
+	}
+	return conn, resp, nil
+}
+
+var defaultDialer Dialer = gorillaDialer{websocket.DefaultDialer}
@@ -0,0 +1,408 @@
+package k256
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sseEventNames maps the event: field of an SSE frame to the MessageType it
+// carries, the inverse of how the gateway names each binary message type on
+// the wire.
+var sseEventNames = map[string]MessageType{
+	"pool_update":       MessageTypePoolUpdate,
+	"pool_update_batch": MessageTypePoolUpdateBatch,
+	"priority_fees":     MessageTypePriorityFees,
+	"blockhash":         MessageTypeBlockhash,
+	"quote":             MessageTypeQuote,
+	"heartbeat":         MessageTypeHeartbeat,
+	"error":             MessageTypeError,
+}
+
+// EventConfig holds the configuration for the SSE client.
+type EventConfig struct {
+	// APIKey is the K256 API key
+	APIKey string
+	// Endpoint is the SSE endpoint URL
+	Endpoint string
+	// Reconnect enables automatic reconnection
+	Reconnect bool
+	// ReconnectDelayInitial is the initial reconnect delay
+	ReconnectDelayInitial time.Duration
+	// ReconnectDelayMax is the maximum reconnect delay
+	ReconnectDelayMax time.Duration
+}
+
+// DefaultEventConfig returns an EventConfig with default values.
+func DefaultEventConfig() EventConfig {
+	return EventConfig{
+		Endpoint:              "https://gateway.k256.xyz/v1/events",
+		Reconnect:             true,
+		ReconnectDelayInitial: time.Second,
+		ReconnectDelayMax:     60 * time.Second,
+	}
+}
+
+// EventClient is a Server-Sent-Events sibling to WebSocketClient: it streams
+// the same message types over a long-lived HTTP response instead of a
+// WebSocket, which plays nicer with proxies that block upgrades. It shares
+// WebSocketClient's callback registration surface (OnPoolUpdate, OnFeeMarket,
+// OnBlockhash, OnQuote, OnHeartbeat, OnError, OnConnected, OnDisconnected).
+type EventClient struct {
+	config     EventConfig
+	httpClient *http.Client
+	mu         sync.RWMutex
+
+	running        bool
+	reconnectDelay time.Duration
+	connected      bool
+	cancel         context.CancelFunc
+
+	// lastSequence is the Sequence of the last successfully processed
+	// PoolUpdate, sent back as the Last-Event-ID header on reconnect so the
+	// gateway can resume the stream instead of replaying it from scratch.
+	lastSequence uint64
+
+	onPoolUpdate   func(*PoolUpdate)
+	onFeeMarket    func(*FeeMarket)
+	onBlockhash    func(*Blockhash)
+	onQuote        func(*Quote)
+	onHeartbeat    func(*Heartbeat)
+	onError        func(error)
+	onConnected    func()
+	onDisconnected func()
+}
+
+// NewEventClient creates a new SSE client with the given configuration.
+func NewEventClient(config EventConfig) *EventClient {
+	if config.Endpoint == "" {
+		config.Endpoint = "https://gateway.k256.xyz/v1/events"
+	}
+	if config.ReconnectDelayInitial == 0 {
+		config.ReconnectDelayInitial = time.Second
+	}
+	if config.ReconnectDelayMax == 0 {
+		config.ReconnectDelayMax = 60 * time.Second
+	}
+
+	return &EventClient{
+		config:         config,
+		httpClient:     &http.Client{},
+		reconnectDelay: config.ReconnectDelayInitial,
+	}
+}
+
+// OnPoolUpdate registers a callback for pool updates.
+func (c *EventClient) OnPoolUpdate(callback func(*PoolUpdate)) {
+	c.onPoolUpdate = callback
+}
+
+// OnFeeMarket registers a callback for fee market updates.
+func (c *EventClient) OnFeeMarket(callback func(*FeeMarket)) {
+	c.onFeeMarket = callback
+}
+
+// OnBlockhash registers a callback for blockhash updates.
+func (c *EventClient) OnBlockhash(callback func(*Blockhash)) {
+	c.onBlockhash = callback
+}
+
+// OnQuote registers a callback for quote updates.
+func (c *EventClient) OnQuote(callback func(*Quote)) {
+	c.onQuote = callback
+}
+
+// OnHeartbeat registers a callback for heartbeat messages.
+func (c *EventClient) OnHeartbeat(callback func(*Heartbeat)) {
+	c.onHeartbeat = callback
+}
+
+// OnError registers a callback for errors.
+func (c *EventClient) OnError(callback func(error)) {
+	c.onError = callback
+}
+
+// OnConnected registers a callback for connection established.
+func (c *EventClient) OnConnected(callback func()) {
+	c.onConnected = callback
+}
+
+// OnDisconnected registers a callback for disconnection.
+func (c *EventClient) OnDisconnected(callback func()) {
+	c.onDisconnected = callback
+}
+
+// IsConnected returns true if the SSE stream is currently open.
+func (c *EventClient) IsConnected() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.connected
+}
+
+// Connect opens the SSE stream and blocks, reconnecting with exponential
+// backoff, until Close is called or a non-reconnecting error occurs.
+func (c *EventClient) Connect() error {
+	c.running = true
+	return c.connectLoop()
+}
+
+func (c *EventClient) connectLoop() error {
+	for c.running {
+		err := c.connect()
+		if err != nil {
+			log.Printf("SSE connection error: %v", err)
+			if c.onError != nil {
+				c.onError(err)
+			}
+		}
+
+		c.mu.Lock()
+		c.connected = false
+		c.mu.Unlock()
+
+		if c.onDisconnected != nil {
+			c.onDisconnected()
+		}
+
+		if !c.running || !c.config.Reconnect {
+			return err
+		}
+
+		jitter := time.Duration(rand.Float64() * float64(500*time.Millisecond))
+		delay := c.reconnectDelay + jitter
+		if delay > c.config.ReconnectDelayMax {
+			delay = c.config.ReconnectDelayMax
+		}
+
+		log.Printf("Reconnecting SSE stream in %v...", delay)
+		time.Sleep(delay)
+
+		c.reconnectDelay *= 2
+		if c.reconnectDelay > c.config.ReconnectDelayMax {
+			c.reconnectDelay = c.config.ReconnectDelayMax
+		}
+	}
+
+	return nil
+}
+
+func (c *EventClient) connect() error {
+	u, err := url.Parse(c.config.Endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid endpoint: %w", err)
+	}
+
+	q := u.Query()
+	q.Set("apiKey", c.config.APIKey)
+	u.RawQuery = q.Encode()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.mu.Lock()
+	c.cancel = cancel
+	c.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	c.mu.RLock()
+	lastSequence := c.lastSequence
+	c.mu.RUnlock()
+	if lastSequence > 0 {
+		req.Header.Set("Last-Event-ID", strconv.FormatUint(lastSequence, 10))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("connect error: %w", err)
+	}
+	defer resp.Body.Close()
+	defer cancel()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	c.mu.Lock()
+	c.connected = true
+	c.reconnectDelay = c.config.ReconnectDelayInitial
+	c.mu.Unlock()
+
+	log.Println("Connected to K256 SSE stream")
+	if c.onConnected != nil {
+		c.onConnected()
+	}
+
+	return c.readStream(resp.Body)
+}
+
+// sseFrame accumulates one event's fields as they're read line by line.
+type sseFrame struct {
+	event string
+	data  strings.Builder
+}
+
+func (c *EventClient) readStream(body io.Reader) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	frame := &sseFrame{}
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case line == "":
+			c.dispatchFrame(frame)
+			frame = &sseFrame{}
+
+		case strings.HasPrefix(line, "event:"):
+			frame.event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+
+		case strings.HasPrefix(line, "data:"):
+			if frame.data.Len() > 0 {
+				frame.data.WriteByte('\n')
+			}
+			frame.data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+
+		case strings.HasPrefix(line, ":"):
+			// comment / keepalive line, ignore
+
+		default:
+			// id:, retry: and anything else we don't act on
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read error: %w", err)
+	}
+	return nil
+}
+
+func (c *EventClient) dispatchFrame(frame *sseFrame) {
+	if frame.event == "" || frame.data.Len() == 0 {
+		return
+	}
+
+	msgType, ok := sseEventNames[frame.event]
+	if !ok {
+		return
+	}
+
+	if msgType == MessageTypeError {
+		errMsg := frame.data.String()
+		log.Printf("Server error: %s", errMsg)
+		if c.onError != nil {
+			c.onError(fmt.Errorf("server error: %s", errMsg))
+		}
+		return
+	}
+
+	payload, err := base64.StdEncoding.DecodeString(frame.data.String())
+	if err != nil {
+		log.Printf("Error decoding SSE payload: %v", err)
+		return
+	}
+
+	switch msgType {
+	case MessageTypePoolUpdate:
+		update, err := DecodePoolUpdate(payload)
+		if err != nil {
+			log.Printf("Error decoding pool update: %v", err)
+			return
+		}
+		c.recordSequence(update.Sequence)
+		if c.onPoolUpdate != nil {
+			c.onPoolUpdate(update)
+		}
+
+	case MessageTypePoolUpdateBatch:
+		updates, err := DecodePoolUpdateBatch(payload)
+		if err != nil {
+			log.Printf("Error decoding pool update batch: %v", err)
+			return
+		}
+		for _, update := range updates {
+			c.recordSequence(update.Sequence)
+			if c.onPoolUpdate != nil {
+				c.onPoolUpdate(update)
+			}
+		}
+
+	case MessageTypePriorityFees:
+		fees, err := DecodeFeeMarket(payload)
+		if err != nil {
+			log.Printf("Error decoding fee market: %v", err)
+			return
+		}
+		if c.onFeeMarket != nil {
+			c.onFeeMarket(fees)
+		}
+
+	case MessageTypeBlockhash:
+		bh, err := DecodeBlockhash(payload)
+		if err != nil {
+			log.Printf("Error decoding blockhash: %v", err)
+			return
+		}
+		if c.onBlockhash != nil {
+			c.onBlockhash(bh)
+		}
+
+	case MessageTypeQuote:
+		quote, err := DecodeQuote(payload)
+		if err != nil {
+			log.Printf("Error decoding quote: %v", err)
+			return
+		}
+		if c.onQuote != nil {
+			c.onQuote(quote)
+		}
+
+	case MessageTypeHeartbeat:
+		hb, err := DecodeHeartbeat(payload)
+		if err != nil {
+			log.Printf("Error decoding heartbeat: %v", err)
+			return
+		}
+		if c.onHeartbeat != nil {
+			c.onHeartbeat(hb)
+		}
+	}
+}
+
+// recordSequence remembers seq as the resume point for the Last-Event-ID
+// header on the next reconnect.
+func (c *EventClient) recordSequence(seq uint64) {
+	c.mu.Lock()
+	c.lastSequence = seq
+	c.mu.Unlock()
+}
+
+// Close stops the SSE stream, canceling any in-flight request so Connect
+// returns promptly instead of waiting for the next line from the gateway.
+func (c *EventClient) Close() error {
+	c.running = false
+
+	c.mu.Lock()
+	cancel := c.cancel
+	c.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	return nil
+}
@@ -33,13 +33,16 @@ func main() {
 		fmt.Println("Connected to K256 WebSocket")
 		
 		// Subscribe to channels after connection is established
-		if err := ws.Subscribe(k256.SubscribeRequest{
-			Channels: []string{"pools", "priority_fees", "blockhash"},
-		}); err != nil {
-			log.Printf("Subscribe error: %v", err)
-		} else {
-			fmt.Println("Subscribed to pools, priority_fees, and blockhash channels")
+		if _, err := ws.SubscribeAllPools(func(update *k256.PoolUpdate) {}); err != nil {
+			log.Printf("SubscribeAllPools error: %v", err)
 		}
+		if _, err := ws.SubscribeFees(func(fees *k256.FeeMarket) {}); err != nil {
+			log.Printf("SubscribeFees error: %v", err)
+		}
+		if _, err := ws.SubscribeBlockhash(func(bh *k256.Blockhash) {}); err != nil {
+			log.Printf("SubscribeBlockhash error: %v", err)
+		}
+		fmt.Println("Subscribed to pools, fees, and blockhash channels")
 	})
 
 	ws.OnDisconnected(func() {
@@ -60,14 +63,13 @@ func main() {
 		}
 	})
 
-	// Handle priority fees
-	ws.OnPriorityFees(func(fees *k256.PriorityFees) {
-		fmt.Printf("[Priority Fees] slot=%d, recommended=%d microlamports\n", 
+	// Handle fee market updates
+	ws.OnFeeMarket(func(fees *k256.FeeMarket) {
+		fmt.Printf("[Fee Market] slot=%d, recommended=%d microlamports/CU\n",
 			fees.Slot, fees.Recommended)
-		fmt.Printf("  State: %d, IsStale: %v\n", fees.State, fees.IsStale)
-		fmt.Printf("  Swap percentiles: p50=%d, p75=%d, p90=%d, p99=%d\n",
-			fees.SwapP50, fees.SwapP75, fees.SwapP90, fees.SwapP99)
-		fmt.Printf("  Samples: %d\n", fees.SwapSamples)
+		fmt.Printf("  State: %v, IsStale: %v\n", fees.State, fees.IsStale)
+		fmt.Printf("  Block utilization: %.1f%% over %d blocks\n",
+			fees.BlockUtilizationPct, fees.BlocksInWindow)
 	})
 
 	// Handle blockhash updates
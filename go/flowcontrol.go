@@ -0,0 +1,275 @@
+package k256
+
+import (
+	"sync"
+	"time"
+)
+
+// CriticalPriority is the SetPriority threshold at which a MessageType's
+// buffer stops applying its DropPolicy: once a type is this important, its
+// buffer is allowed to grow past Capacity rather than shed a message. Pass
+// this (or higher) to SetPriority for any type that must never be dropped.
+// Under Coalesce, a message for a PoolAddress already queued is still merged
+// (or dropped if stale) at any priority.
+const CriticalPriority = 10
+
+// DropPolicy selects how a flow-controlled buffer sheds load once it's
+// full.
+type DropPolicy int
+
+const (
+	// DropOldest discards the buffer's oldest queued message to make room
+	// for the incoming one.
+	DropOldest DropPolicy = iota
+	// DropNewest discards the incoming message instead of anything queued.
+	DropNewest
+	// Coalesce keeps only the most recent message per PoolAddress (the
+	// highest WriteVersion seen), so a slow consumer sees the latest state
+	// instead of a backlog of superseded deltas. Unlike the other policies,
+	// this merging happens on every push, whether or not the buffer is full.
+	// For message types without a PoolAddress it behaves like DropOldest.
+	Coalesce
+)
+
+// FlowConfig configures the bounded buffer sitting between the reader
+// goroutine and a MessageType's callbacks, so a slow OnPoolUpdate can't
+// block OnHeartbeat or OnBlockhash from being delivered. Inspired by
+// go-ethereum's les/flowcontrol client buffers.
+type FlowConfig struct {
+	// Capacity is the maximum number of messages held in the buffer before
+	// Policy kicks in.
+	Capacity int
+	// RechargeRate caps how many messages/sec are delivered to callbacks
+	// for this type. 0 means unlimited.
+	RechargeRate float64
+	// Policy selects what happens when the buffer is at Capacity.
+	Policy DropPolicy
+}
+
+// DefaultFlowConfig is used for any MessageType without an explicit
+// SetFlowConfig call: a generous, unpaced buffer that drops the oldest
+// message on overflow.
+func DefaultFlowConfig() FlowConfig {
+	return FlowConfig{Capacity: 1000, Policy: DropOldest}
+}
+
+// FlowStats reports the live counters for one MessageType's buffer.
+type FlowStats struct {
+	Dropped    uint64
+	Coalesced  uint64
+	QueueDepth int
+}
+
+// flowItem is one message awaiting delivery. poolAddress/writeVersion are
+// only populated for PoolUpdate-shaped payloads, where they drive Coalesce.
+type flowItem struct {
+	payload      interface{}
+	poolAddress  string
+	writeVersion uint64
+}
+
+// flowBuffer is the per-MessageType bounded buffer: a queue, drained by a
+// dedicated goroutine (started by run), so one type's slow callback can't
+// hold up another's.
+type flowBuffer struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	config   FlowConfig
+	priority int
+	queue    []flowItem
+	closed   bool
+
+	dropped   uint64
+	coalesced uint64
+
+	tokens     float64
+	lastRefill time.Time
+}
+
+func newFlowBuffer(config FlowConfig) *flowBuffer {
+	b := &flowBuffer{config: config, tokens: config.RechargeRate, lastRefill: time.Now()}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+func (b *flowBuffer) setConfig(config FlowConfig) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.config = config
+}
+
+func (b *flowBuffer) setPriority(priority int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.priority = priority
+}
+
+// push enqueues item, first merging it into a queued item for the same
+// PoolAddress under Coalesce, then applying Policy if the buffer is full and
+// the type isn't exempt via SetPriority.
+func (b *flowBuffer) push(item flowItem) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.config.Policy == Coalesce && item.poolAddress != "" {
+		for i := range b.queue {
+			if b.queue[i].poolAddress != item.poolAddress {
+				continue
+			}
+			if item.writeVersion >= b.queue[i].writeVersion {
+				b.queue[i] = item
+				b.coalesced++
+			} else {
+				b.dropped++ // stale relative to what's already queued
+			}
+			b.cond.Signal()
+			return
+		}
+	}
+
+	if len(b.queue) >= b.config.Capacity && b.priority < CriticalPriority {
+		switch b.config.Policy {
+		case DropNewest:
+			b.dropped++
+			return
+		default: // DropOldest, Coalesce (no PoolAddress match found above)
+			b.queue = b.queue[1:]
+			b.dropped++
+		}
+	}
+
+	b.queue = append(b.queue, item)
+	b.cond.Signal()
+}
+
+func (b *flowBuffer) stats() FlowStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return FlowStats{Dropped: b.dropped, Coalesced: b.coalesced, QueueDepth: len(b.queue)}
+}
+
+func (b *flowBuffer) close() {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+	b.cond.Broadcast()
+}
+
+// run drains the buffer until close is called and it's empty, pacing
+// deliveries to RechargeRate and invoking deliver for each item. It's
+// meant to be run in its own goroutine, one per MessageType.
+func (b *flowBuffer) run(deliver func(interface{})) {
+	for {
+		b.mu.Lock()
+		for len(b.queue) == 0 && !b.closed {
+			b.cond.Wait()
+		}
+		if len(b.queue) == 0 && b.closed {
+			b.mu.Unlock()
+			return
+		}
+		item := b.queue[0]
+		b.queue = b.queue[1:]
+		b.mu.Unlock()
+
+		b.throttle()
+		deliver(item.payload)
+	}
+}
+
+// throttle blocks until a token is available, if RechargeRate is set.
+func (b *flowBuffer) throttle() {
+	b.mu.Lock()
+	rate := b.config.RechargeRate
+	if rate <= 0 {
+		b.mu.Unlock()
+		return
+	}
+
+	now := time.Now()
+	b.tokens += rate * now.Sub(b.lastRefill).Seconds()
+	if b.tokens > rate {
+		b.tokens = rate // cap burst to ~1s worth of headroom
+	}
+	b.lastRefill = now
+
+	var wait time.Duration
+	if b.tokens < 1 {
+		wait = time.Duration((1 - b.tokens) / rate * float64(time.Second))
+		b.tokens = 0
+	} else {
+		b.tokens--
+	}
+	b.mu.Unlock()
+
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+// flowController owns one flowBuffer per MessageType in use, each drained
+// by its own goroutine once started.
+type flowController struct {
+	mu         sync.Mutex
+	buffers    map[MessageType]*flowBuffer
+	deliverers map[MessageType]func(interface{})
+	started    bool
+}
+
+func newFlowController() *flowController {
+	return &flowController{buffers: make(map[MessageType]*flowBuffer)}
+}
+
+func (fc *flowController) bufferFor(msgType MessageType) *flowBuffer {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	buf, ok := fc.buffers[msgType]
+	if !ok {
+		buf = newFlowBuffer(DefaultFlowConfig())
+		fc.buffers[msgType] = buf
+		if fc.started {
+			go buf.run(fc.deliverers[msgType])
+		}
+	}
+	return buf
+}
+
+func (fc *flowController) setConfig(msgType MessageType, config FlowConfig) {
+	fc.bufferFor(msgType).setConfig(config)
+}
+
+func (fc *flowController) setPriority(msgType MessageType, priority int) {
+	fc.bufferFor(msgType).setPriority(priority)
+}
+
+func (fc *flowController) stats(msgType MessageType) FlowStats {
+	return fc.bufferFor(msgType).stats()
+}
+
+func (fc *flowController) push(msgType MessageType, item flowItem) {
+	fc.bufferFor(msgType).push(item)
+}
+
+// start begins draining every buffer registered so far (and any created
+// later) with deliverers, which maps a MessageType to the function that
+// applies its callbacks. Call once per client.
+func (fc *flowController) start(deliverers map[MessageType]func(interface{})) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	fc.deliverers = deliverers
+	fc.started = true
+	for msgType, buf := range fc.buffers {
+		go buf.run(deliverers[msgType])
+	}
+}
+
+func (fc *flowController) close() {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	for _, buf := range fc.buffers {
+		buf.close()
+	}
+}
@@ -0,0 +1,78 @@
+package k256
+
+import "testing"
+
+func (b *flowBuffer) queueSnapshot() []flowItem {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]flowItem(nil), b.queue...)
+}
+
+func TestFlowBufferDropOldest(t *testing.T) {
+	b := newFlowBuffer(FlowConfig{Capacity: 2, Policy: DropOldest})
+	b.push(flowItem{payload: 1})
+	b.push(flowItem{payload: 2})
+	b.push(flowItem{payload: 3})
+
+	queue := b.queueSnapshot()
+	if len(queue) != 2 || queue[0].payload != 2 || queue[1].payload != 3 {
+		t.Fatalf("queue = %v, want [2 3] (oldest dropped)", queue)
+	}
+	if got := b.stats().Dropped; got != 1 {
+		t.Fatalf("Dropped = %d, want 1", got)
+	}
+}
+
+func TestFlowBufferDropNewest(t *testing.T) {
+	b := newFlowBuffer(FlowConfig{Capacity: 2, Policy: DropNewest})
+	b.push(flowItem{payload: 1})
+	b.push(flowItem{payload: 2})
+	b.push(flowItem{payload: 3})
+
+	queue := b.queueSnapshot()
+	if len(queue) != 2 || queue[0].payload != 1 || queue[1].payload != 2 {
+		t.Fatalf("queue = %v, want [1 2] (newest dropped)", queue)
+	}
+	if got := b.stats().Dropped; got != 1 {
+		t.Fatalf("Dropped = %d, want 1", got)
+	}
+}
+
+func TestFlowBufferCoalesce(t *testing.T) {
+	b := newFlowBuffer(FlowConfig{Capacity: 10, Policy: Coalesce})
+	b.push(flowItem{payload: "v1", poolAddress: "pool-a", writeVersion: 1})
+	b.push(flowItem{payload: "v2", poolAddress: "pool-a", writeVersion: 2})
+	b.push(flowItem{payload: "stale", poolAddress: "pool-a", writeVersion: 1})
+
+	queue := b.queueSnapshot()
+	if len(queue) != 1 || queue[0].payload != "v2" {
+		t.Fatalf("queue = %v, want a single v2 entry", queue)
+	}
+
+	stats := b.stats()
+	if stats.Coalesced != 1 {
+		t.Fatalf("Coalesced = %d, want 1", stats.Coalesced)
+	}
+	if stats.Dropped != 1 {
+		t.Fatalf("Dropped = %d, want 1 (stale write superseded by what's queued)", stats.Dropped)
+	}
+}
+
+// TestFlowBufferCriticalPriorityExempt guards the SetPriority guarantee:
+// once a buffer's priority reaches CriticalPriority, it must grow past
+// Capacity rather than drop anything.
+func TestFlowBufferCriticalPriorityExempt(t *testing.T) {
+	b := newFlowBuffer(FlowConfig{Capacity: 1, Policy: DropOldest})
+	b.setPriority(CriticalPriority)
+
+	for i := 0; i < 5; i++ {
+		b.push(flowItem{payload: i})
+	}
+
+	if got := b.stats().Dropped; got != 0 {
+		t.Fatalf("Dropped = %d, want 0 once priority reaches CriticalPriority", got)
+	}
+	if depth := len(b.queueSnapshot()); depth != 5 {
+		t.Fatalf("queue depth = %d, want 5 (buffer grows past Capacity once exempt)", depth)
+	}
+}
@@ -25,9 +25,7 @@
 //			panic(err)
 //		}
 //
-//		ws.Subscribe(k256.SubscribeRequest{
-//			Channels: []string{"pools", "priority_fees", "blockhash"},
-//		})
+//		ws.SubscribeAllPools(func(update *k256.PoolUpdate) {})
 //
 //		select {}
 //	}
@@ -39,6 +39,9 @@ func DefaultLeaderConfig() LeaderConfig {
 }
 
 // LeaderHandler is called for each decoded message from the leader-schedule WS.
+// It is an optional catch-all: pass nil and use the typed On* registration
+// methods (e.g. OnGossipDiff) instead if a type switch over the raw envelope
+// isn't needed.
 type LeaderHandler func(msg LeaderMessage)
 
 // LeaderWebSocketClient connects to the K256 leader-schedule WebSocket (JSON mode).
@@ -49,6 +52,16 @@ type LeaderWebSocketClient struct {
 	running        bool
 	reconnectDelay time.Duration
 	handler        LeaderHandler
+	dispatch       leaderDispatch
+	recorder       *Recorder
+}
+
+// SetRecorder attaches a Recorder that captures every inbound frame for later
+// offline replay via NewReplayClient. Pass nil to stop recording.
+func (c *LeaderWebSocketClient) SetRecorder(r *Recorder) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.recorder = r
 }
 
 // NewLeaderClient creates a new leader-schedule WebSocket client.
@@ -68,6 +81,36 @@ func NewLeaderClient(config LeaderConfig, handler LeaderHandler) *LeaderWebSocke
 
 // Connect establishes the WebSocket connection and starts reading messages.
 func (c *LeaderWebSocketClient) Connect() error {
+	if err := c.dial(); err != nil {
+		return err
+	}
+	go c.readLoop()
+	return nil
+}
+
+// Reconnect tears down the current connection and dials a fresh one in its
+// place, without starting a second readLoop goroutine. Use this instead of
+// Close+Connect when the caller is itself running on the readLoop goroutine
+// (e.g. LeaderState.forceResubscribe, invoked from the handler readLoop
+// calls inline) — readLoop re-reads c.conn at the top of every iteration,
+// so once Reconnect returns, the existing goroutine picks up the new
+// connection on its own next iteration instead of racing a second
+// goroutine's ReadMessage call against it.
+func (c *LeaderWebSocketClient) Reconnect() error {
+	c.mu.Lock()
+	conn := c.conn
+	c.conn = nil
+	c.mu.Unlock()
+	if conn != nil {
+		conn.Close()
+	}
+
+	return c.dial()
+}
+
+// dial opens the WebSocket connection and sends the subscribe handshake,
+// without starting a reader goroutine.
+func (c *LeaderWebSocketClient) dial() error {
 	u, err := url.Parse(c.config.Endpoint)
 	if err != nil {
 		return fmt.Errorf("invalid endpoint URL: %w", err)
@@ -93,14 +136,26 @@ func (c *LeaderWebSocketClient) Connect() error {
 		Channels: c.config.Channels,
 		Format:   "json",
 	}
-	if err := conn.WriteJSON(sub); err != nil {
+	subBytes, err := json.Marshal(sub)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("marshal subscribe request: %w", err)
+	}
+	if err := conn.WriteMessage(websocket.TextMessage, subBytes); err != nil {
 		conn.Close()
 		return fmt.Errorf("subscribe failed: %w", err)
 	}
 
-	log.Printf("[LeaderWS] Connected to %s, subscribed to %v", c.config.Endpoint, c.config.Channels)
+	c.mu.RLock()
+	recorder := c.recorder
+	c.mu.RUnlock()
+	if recorder != nil {
+		if err := recorder.record(DirectionOutbound, subBytes); err != nil {
+			log.Printf("[LeaderWS] Recorder error: %v", err)
+		}
+	}
 
-	go c.readLoop()
+	log.Printf("[LeaderWS] Connected to %s, subscribed to %v", c.config.Endpoint, c.config.Channels)
 	return nil
 }
 
@@ -122,6 +177,7 @@ func (c *LeaderWebSocketClient) readLoop() {
 		c.mu.RLock()
 		conn := c.conn
 		running := c.running
+		recorder := c.recorder
 		c.mu.RUnlock()
 
 		if !running || conn == nil {
@@ -138,6 +194,12 @@ func (c *LeaderWebSocketClient) readLoop() {
 			return
 		}
 
+		if recorder != nil {
+			if err := recorder.record(DirectionInbound, message); err != nil {
+				log.Printf("[LeaderWS] Recorder error: %v", err)
+			}
+		}
+
 		var msg LeaderMessage
 		if err := json.Unmarshal(message, &msg); err != nil {
 			log.Printf("[LeaderWS] Parse error: %v", err)
@@ -147,6 +209,7 @@ func (c *LeaderWebSocketClient) readLoop() {
 		if c.handler != nil {
 			c.handler(msg)
 		}
+		c.dispatch.dispatch(msg)
 	}
 }
 
@@ -0,0 +1,255 @@
+package k256
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+)
+
+// leaderDispatch holds the typed callback registrations for a
+// LeaderWebSocketClient, keyed by message type. Multiple callbacks may be
+// registered per type; they are invoked in registration order.
+type leaderDispatch struct {
+	mu sync.RWMutex
+
+	onGossipSnapshot  []func(*GossipSnapshotData)
+	onGossipDiff      []func(*GossipDiffData)
+	onSlotUpdate      []func(*SlotUpdateData)
+	onRoutingHealth   []func(*RoutingHealthData)
+	onSkipEvent       []func(*SkipEventData)
+	onIpChange        []func(*IpChangeData)
+	onLeaderHeartbeat []func(*LeaderHeartbeatData)
+	onLeaderSchedule  []func(*LeaderScheduleData)
+	onSubscribed      []func(*LeaderSubscribedData)
+}
+
+// OnGossipSnapshot registers a callback for gossip_snapshot messages.
+func (d *leaderDispatch) OnGossipSnapshot(callback func(*GossipSnapshotData)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.onGossipSnapshot = append(d.onGossipSnapshot, callback)
+}
+
+// OnGossipDiff registers a callback for gossip_diff messages.
+func (d *leaderDispatch) OnGossipDiff(callback func(*GossipDiffData)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.onGossipDiff = append(d.onGossipDiff, callback)
+}
+
+// OnSlotUpdate registers a callback for slot_update messages.
+func (d *leaderDispatch) OnSlotUpdate(callback func(*SlotUpdateData)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.onSlotUpdate = append(d.onSlotUpdate, callback)
+}
+
+// OnRoutingHealth registers a callback for routing_health messages.
+func (d *leaderDispatch) OnRoutingHealth(callback func(*RoutingHealthData)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.onRoutingHealth = append(d.onRoutingHealth, callback)
+}
+
+// OnSkipEvent registers a callback for skip_event messages.
+func (d *leaderDispatch) OnSkipEvent(callback func(*SkipEventData)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.onSkipEvent = append(d.onSkipEvent, callback)
+}
+
+// OnIpChange registers a callback for ip_change messages.
+func (d *leaderDispatch) OnIpChange(callback func(*IpChangeData)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.onIpChange = append(d.onIpChange, callback)
+}
+
+// OnLeaderHeartbeat registers a callback for heartbeat messages.
+func (d *leaderDispatch) OnLeaderHeartbeat(callback func(*LeaderHeartbeatData)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.onLeaderHeartbeat = append(d.onLeaderHeartbeat, callback)
+}
+
+// OnLeaderSchedule registers a callback for leader_schedule messages.
+func (d *leaderDispatch) OnLeaderSchedule(callback func(*LeaderScheduleData)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.onLeaderSchedule = append(d.onLeaderSchedule, callback)
+}
+
+// OnSubscribed registers a callback for the subscribed handshake response.
+func (d *leaderDispatch) OnSubscribed(callback func(*LeaderSubscribedData)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.onSubscribed = append(d.onSubscribed, callback)
+}
+
+// OnGossipSnapshot registers a callback for gossip_snapshot messages.
+func (c *LeaderWebSocketClient) OnGossipSnapshot(callback func(*GossipSnapshotData)) {
+	c.dispatch.OnGossipSnapshot(callback)
+}
+
+// OnGossipDiff registers a callback for gossip_diff messages.
+func (c *LeaderWebSocketClient) OnGossipDiff(callback func(*GossipDiffData)) {
+	c.dispatch.OnGossipDiff(callback)
+}
+
+// OnSlotUpdate registers a callback for slot_update messages.
+func (c *LeaderWebSocketClient) OnSlotUpdate(callback func(*SlotUpdateData)) {
+	c.dispatch.OnSlotUpdate(callback)
+}
+
+// OnRoutingHealth registers a callback for routing_health messages.
+func (c *LeaderWebSocketClient) OnRoutingHealth(callback func(*RoutingHealthData)) {
+	c.dispatch.OnRoutingHealth(callback)
+}
+
+// OnSkipEvent registers a callback for skip_event messages.
+func (c *LeaderWebSocketClient) OnSkipEvent(callback func(*SkipEventData)) {
+	c.dispatch.OnSkipEvent(callback)
+}
+
+// OnIpChange registers a callback for ip_change messages.
+func (c *LeaderWebSocketClient) OnIpChange(callback func(*IpChangeData)) {
+	c.dispatch.OnIpChange(callback)
+}
+
+// OnLeaderHeartbeat registers a callback for heartbeat messages.
+func (c *LeaderWebSocketClient) OnLeaderHeartbeat(callback func(*LeaderHeartbeatData)) {
+	c.dispatch.OnLeaderHeartbeat(callback)
+}
+
+// OnLeaderSchedule registers a callback for leader_schedule messages.
+func (c *LeaderWebSocketClient) OnLeaderSchedule(callback func(*LeaderScheduleData)) {
+	c.dispatch.OnLeaderSchedule(callback)
+}
+
+// OnSubscribed registers a callback for the subscribed handshake response.
+func (c *LeaderWebSocketClient) OnSubscribed(callback func(*LeaderSubscribedData)) {
+	c.dispatch.OnSubscribed(callback)
+}
+
+// dispatch decodes msg.Data according to msg.Type and invokes every callback
+// registered for that type. Decode errors are logged and otherwise ignored,
+// matching the tolerance of the raw LeaderHandler path.
+func (d *leaderDispatch) dispatch(msg LeaderMessage) {
+	switch msg.Type {
+	case MsgGossipSnapshot:
+		var data GossipSnapshotData
+		if !decodeLeaderData(msg, &data) {
+			return
+		}
+		d.mu.RLock()
+		callbacks := append([]func(*GossipSnapshotData){}, d.onGossipSnapshot...)
+		d.mu.RUnlock()
+		for _, cb := range callbacks {
+			cb(&data)
+		}
+
+	case MsgGossipDiff:
+		var data GossipDiffData
+		if !decodeLeaderData(msg, &data) {
+			return
+		}
+		d.mu.RLock()
+		callbacks := append([]func(*GossipDiffData){}, d.onGossipDiff...)
+		d.mu.RUnlock()
+		for _, cb := range callbacks {
+			cb(&data)
+		}
+
+	case MsgSlotUpdate:
+		var data SlotUpdateData
+		if !decodeLeaderData(msg, &data) {
+			return
+		}
+		d.mu.RLock()
+		callbacks := append([]func(*SlotUpdateData){}, d.onSlotUpdate...)
+		d.mu.RUnlock()
+		for _, cb := range callbacks {
+			cb(&data)
+		}
+
+	case MsgRoutingHealth:
+		var data RoutingHealthData
+		if !decodeLeaderData(msg, &data) {
+			return
+		}
+		d.mu.RLock()
+		callbacks := append([]func(*RoutingHealthData){}, d.onRoutingHealth...)
+		d.mu.RUnlock()
+		for _, cb := range callbacks {
+			cb(&data)
+		}
+
+	case MsgSkipEvent:
+		var data SkipEventData
+		if !decodeLeaderData(msg, &data) {
+			return
+		}
+		d.mu.RLock()
+		callbacks := append([]func(*SkipEventData){}, d.onSkipEvent...)
+		d.mu.RUnlock()
+		for _, cb := range callbacks {
+			cb(&data)
+		}
+
+	case MsgIpChange:
+		var data IpChangeData
+		if !decodeLeaderData(msg, &data) {
+			return
+		}
+		d.mu.RLock()
+		callbacks := append([]func(*IpChangeData){}, d.onIpChange...)
+		d.mu.RUnlock()
+		for _, cb := range callbacks {
+			cb(&data)
+		}
+
+	case MsgLeaderHeartbeat:
+		var data LeaderHeartbeatData
+		if !decodeLeaderData(msg, &data) {
+			return
+		}
+		d.mu.RLock()
+		callbacks := append([]func(*LeaderHeartbeatData){}, d.onLeaderHeartbeat...)
+		d.mu.RUnlock()
+		for _, cb := range callbacks {
+			cb(&data)
+		}
+
+	case MsgLeaderSchedule:
+		var data LeaderScheduleData
+		if !decodeLeaderData(msg, &data) {
+			return
+		}
+		d.mu.RLock()
+		callbacks := append([]func(*LeaderScheduleData){}, d.onLeaderSchedule...)
+		d.mu.RUnlock()
+		for _, cb := range callbacks {
+			cb(&data)
+		}
+
+	case MsgLeaderSubscribed:
+		var data LeaderSubscribedData
+		if !decodeLeaderData(msg, &data) {
+			return
+		}
+		d.mu.RLock()
+		callbacks := append([]func(*LeaderSubscribedData){}, d.onSubscribed...)
+		d.mu.RUnlock()
+		for _, cb := range callbacks {
+			cb(&data)
+		}
+	}
+}
+
+func decodeLeaderData(msg LeaderMessage, out interface{}) bool {
+	if err := json.Unmarshal(msg.Data, out); err != nil {
+		log.Printf("[LeaderWS] failed to decode %s payload: %v", msg.Type, err)
+		return false
+	}
+	return true
+}
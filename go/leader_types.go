@@ -1,5 +1,7 @@
 package k256
 
+import "encoding/json"
+
 // Leader Schedule WebSocket message types.
 // All messages are JSON text frames with: type, kind, key (optional), data.
 
@@ -19,6 +21,19 @@ var AllLeaderChannels = []string{
 	LeaderChannelAlerts,
 }
 
+// Leader-schedule WS message type tags, i.e. the values of LeaderMessage.Type.
+const (
+	MsgGossipSnapshot   = "gossip_snapshot"
+	MsgGossipDiff       = "gossip_diff"
+	MsgSlotUpdate       = "slot_update"
+	MsgRoutingHealth    = "routing_health"
+	MsgSkipEvent        = "skip_event"
+	MsgIpChange         = "ip_change"
+	MsgLeaderHeartbeat  = "heartbeat"
+	MsgLeaderSchedule   = "leader_schedule"
+	MsgLeaderSubscribed = "subscribed"
+)
+
 // MessageKind describes how to consume a message.
 // "snapshot" = full state replacement, "diff" = merge into snapshot, "event" = append-only.
 type MessageKind string
@@ -0,0 +1,207 @@
+package k256
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Direction indicates which way a recorded frame travelled.
+type Direction string
+
+const (
+	// DirectionInbound marks a frame received from the server.
+	DirectionInbound Direction = "in"
+	// DirectionOutbound marks a frame sent to the server.
+	DirectionOutbound Direction = "out"
+)
+
+// RecordedFrame is a single newline-delimited JSON record in a capture file.
+type RecordedFrame struct {
+	TimestampMs int64     `json:"timestamp_ms"`
+	Direction   Direction `json:"direction"`
+	Data        []byte    `json:"data"`
+}
+
+// Recorder appends every frame observed on a LeaderWebSocketClient's
+// connection to a newline-delimited JSON capture file, for later replay via
+// NewReplayClient. Attach it with LeaderWebSocketClient.SetRecorder.
+type Recorder struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewRecorder returns a Recorder that appends frames to w.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{enc: json.NewEncoder(w)}
+}
+
+func (r *Recorder) record(dir Direction, data []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	frame := RecordedFrame{
+		TimestampMs: time.Now().UnixMilli(),
+		Direction:   dir,
+		Data:        data,
+	}
+	return r.enc.Encode(frame)
+}
+
+// Replayer sources leader-schedule WS frames from a file recorded by Recorder
+// instead of a live connection. It mirrors LeaderWebSocketClient's
+// Connect/Close/On* surface so conformance tests can drive it against a fixed
+// vector corpus instead of the live gateway.
+type Replayer struct {
+	mu       sync.RWMutex
+	running  bool
+	handler  LeaderHandler
+	dispatch leaderDispatch
+
+	frames          []RecordedFrame
+	speedMultiplier float64
+}
+
+// NewReplayClient builds a Replayer that reads recorded frames from path and
+// invokes handler (may be nil; use the typed On* methods instead) for each
+// inbound one. Frames are replayed in Connect, honoring their recorded
+// inter-message gaps.
+func NewReplayClient(path string, handler LeaderHandler) (*Replayer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open replay file: %w", err)
+	}
+	defer f.Close()
+
+	var frames []RecordedFrame
+	dec := json.NewDecoder(f)
+	for dec.More() {
+		var frame RecordedFrame
+		if err := dec.Decode(&frame); err != nil {
+			return nil, fmt.Errorf("decode replay frame: %w", err)
+		}
+		frames = append(frames, frame)
+	}
+
+	return &Replayer{
+		handler:         handler,
+		frames:          frames,
+		speedMultiplier: 1,
+	}, nil
+}
+
+// SetSpeedMultiplier scales the delay between replayed frames; 1 (the
+// default) replays at the originally recorded pace, values >1 replay faster,
+// and 0 replays as fast as possible.
+func (r *Replayer) SetSpeedMultiplier(mult float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.speedMultiplier = mult
+}
+
+// Connect starts replaying the recorded frames in a background goroutine and
+// returns immediately, mirroring LeaderWebSocketClient.Connect.
+func (r *Replayer) Connect() error {
+	r.mu.Lock()
+	if r.running {
+		r.mu.Unlock()
+		return fmt.Errorf("replayer already running")
+	}
+	r.running = true
+	r.mu.Unlock()
+
+	go r.replayLoop()
+	return nil
+}
+
+// Close stops replay before the remaining frames have been delivered.
+func (r *Replayer) Close() {
+	r.mu.Lock()
+	r.running = false
+	r.mu.Unlock()
+}
+
+func (r *Replayer) replayLoop() {
+	var lastTimestampMs int64
+	for i, frame := range r.frames {
+		r.mu.RLock()
+		running := r.running
+		speed := r.speedMultiplier
+		r.mu.RUnlock()
+		if !running {
+			return
+		}
+
+		if i > 0 && speed > 0 {
+			gap := time.Duration(frame.TimestampMs-lastTimestampMs) * time.Millisecond
+			time.Sleep(time.Duration(float64(gap) / speed))
+		}
+		lastTimestampMs = frame.TimestampMs
+
+		if frame.Direction != DirectionInbound {
+			continue
+		}
+
+		var msg LeaderMessage
+		if err := json.Unmarshal(frame.Data, &msg); err != nil {
+			continue
+		}
+
+		if r.handler != nil {
+			r.handler(msg)
+		}
+		r.dispatch.dispatch(msg)
+	}
+
+	r.mu.Lock()
+	r.running = false
+	r.mu.Unlock()
+}
+
+// OnGossipSnapshot registers a callback for gossip_snapshot messages.
+func (r *Replayer) OnGossipSnapshot(callback func(*GossipSnapshotData)) {
+	r.dispatch.OnGossipSnapshot(callback)
+}
+
+// OnGossipDiff registers a callback for gossip_diff messages.
+func (r *Replayer) OnGossipDiff(callback func(*GossipDiffData)) {
+	r.dispatch.OnGossipDiff(callback)
+}
+
+// OnSlotUpdate registers a callback for slot_update messages.
+func (r *Replayer) OnSlotUpdate(callback func(*SlotUpdateData)) {
+	r.dispatch.OnSlotUpdate(callback)
+}
+
+// OnRoutingHealth registers a callback for routing_health messages.
+func (r *Replayer) OnRoutingHealth(callback func(*RoutingHealthData)) {
+	r.dispatch.OnRoutingHealth(callback)
+}
+
+// OnSkipEvent registers a callback for skip_event messages.
+func (r *Replayer) OnSkipEvent(callback func(*SkipEventData)) {
+	r.dispatch.OnSkipEvent(callback)
+}
+
+// OnIpChange registers a callback for ip_change messages.
+func (r *Replayer) OnIpChange(callback func(*IpChangeData)) {
+	r.dispatch.OnIpChange(callback)
+}
+
+// OnLeaderHeartbeat registers a callback for heartbeat messages.
+func (r *Replayer) OnLeaderHeartbeat(callback func(*LeaderHeartbeatData)) {
+	r.dispatch.OnLeaderHeartbeat(callback)
+}
+
+// OnLeaderSchedule registers a callback for leader_schedule messages.
+func (r *Replayer) OnLeaderSchedule(callback func(*LeaderScheduleData)) {
+	r.dispatch.OnLeaderSchedule(callback)
+}
+
+// OnSubscribed registers a callback for the subscribed handshake response.
+func (r *Replayer) OnSubscribed(callback func(*LeaderSubscribedData)) {
+	r.dispatch.OnSubscribed(callback)
+}
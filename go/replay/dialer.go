@@ -0,0 +1,121 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	k256 "github.com/k256-xyz/k256-sdks/go"
+)
+
+// FileDialer is a k256.Dialer that replays a capture made by Recorder
+// instead of opening a live connection. Set it as Config.Dialer to drive a
+// k256.WebSocketClient's decoders, gap handling and reconnect/backoff logic
+// from a fixed corpus.
+//
+// Each Dial call replays the capture from the start, so Config.Reconnect
+// can be exercised the same way it would be against a live gateway whose
+// connection drops once the corpus runs out.
+type FileDialer struct {
+	// DataPath is the capture's data file, as written by Recorder.
+	DataPath string
+	// IndexPath is the capture's sidecar index. Defaults to
+	// DataPath+".idx.json".
+	IndexPath string
+	// SpeedMultiplier scales the delay between replayed frames; 1 replays
+	// at the originally recorded pace, values >1 replay faster, and the
+	// zero value (the default) replays as fast as possible.
+	SpeedMultiplier float64
+}
+
+// Dial implements k256.Dialer.
+func (d FileDialer) Dial(urlStr string, requestHeader http.Header) (k256.WSConn, *http.Response, error) {
+	indexPath := d.IndexPath
+	if indexPath == "" {
+		indexPath = d.DataPath + ".idx.json"
+	}
+
+	idxFile, err := os.Open(indexPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open replay index: %w", err)
+	}
+	defer idxFile.Close()
+
+	var index []IndexEntry
+	if err := json.NewDecoder(idxFile).Decode(&index); err != nil {
+		return nil, nil, fmt.Errorf("decode replay index: %w", err)
+	}
+
+	data, err := os.ReadFile(d.DataPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read replay data: %w", err)
+	}
+
+	return &fakeConn{index: index, data: data, speed: d.SpeedMultiplier}, nil, nil
+}
+
+// fakeConn implements k256.WSConn over a capture loaded into memory,
+// yielding one frame per ReadMessage call and discarding writes (the
+// subscribe/unsubscribe frames a WebSocketClient sends have no server to
+// reach in replay mode).
+type fakeConn struct {
+	mu    sync.Mutex
+	index []IndexEntry
+	data  []byte
+	speed float64
+
+	pos             int
+	lastTimestampMs int64
+	closed          bool
+}
+
+func (c *fakeConn) ReadMessage() (int, []byte, error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return 0, nil, fmt.Errorf("replay: connection closed")
+	}
+	if c.pos >= len(c.index) {
+		c.mu.Unlock()
+		return 0, nil, io.EOF
+	}
+
+	entry := c.index[c.pos]
+	first := c.pos == 0
+	gapMs := entry.TimestampMs - c.lastTimestampMs
+	speed := c.speed
+	c.lastTimestampMs = entry.TimestampMs
+	c.pos++
+	c.mu.Unlock()
+
+	if !first && speed > 0 {
+		time.Sleep(time.Duration(float64(gapMs)/speed) * time.Millisecond)
+	}
+
+	dec := k256.NewDecoder(bytes.NewReader(c.data[entry.Offset:]))
+	msgType, payload, err := dec.Decode()
+	if err != nil {
+		return 0, nil, fmt.Errorf("decode replay frame: %w", err)
+	}
+
+	frame := make([]byte, 1+len(payload))
+	frame[0] = byte(msgType)
+	copy(frame[1:], payload)
+	return binaryMessage, frame, nil
+}
+
+func (c *fakeConn) WriteMessage(int, []byte) error {
+	return nil
+}
+
+func (c *fakeConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	return nil
+}
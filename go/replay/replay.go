@@ -0,0 +1,136 @@
+// Package replay captures a live WebSocketClient session to disk and
+// replays it back through a fake k256.Dialer, so conformance-style tests
+// can exercise decoding, gap handling and reconnect/backoff against a fixed
+// corpus instead of the live gateway.
+//
+// A capture is two files: a data file of k256.Encoder frames (type-tagged
+// and length-prefixed; the length prefix is added on capture, since a
+// binary WebSocket frame is just the type byte followed by the payload) and
+// a sidecar JSON index of each frame's offset, timestamp and sequence number.
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	k256 "github.com/k256-xyz/k256-sdks/go"
+)
+
+// binaryMessage is the WebSocket opcode for a binary frame (RFC 6455), used
+// directly rather than imported so this package doesn't need to depend on
+// gorilla/websocket.
+const binaryMessage = 2
+
+// IndexEntry describes one captured frame: where it lives in the data file
+// and the metadata needed to drive replay pacing and gap-handling tests.
+type IndexEntry struct {
+	Offset      int64  `json:"offset"`
+	Length      uint32 `json:"length"`
+	MessageType uint8  `json:"message_type"`
+	TimestampMs int64  `json:"timestamp_ms"`
+	// Sequence is populated for PoolUpdate frames, for building corpora that
+	// exercise gap detection.
+	Sequence uint64 `json:"sequence,omitempty"`
+}
+
+// Recorder wraps a live k256.WSConn, appending every inbound binary frame
+// to a capture file as it's read. Use it in place of the real connection
+// (e.g. by wrapping the *websocket.Conn a k256.Dialer produces) to build a
+// corpus for later replay via FileDialer.
+type Recorder struct {
+	k256.WSConn
+
+	mu        sync.Mutex
+	data      *os.File
+	enc       *k256.Encoder
+	index     []IndexEntry
+	indexPath string
+}
+
+// NewRecorder creates dataPath and returns a Recorder that appends frames
+// read from conn to it. The sidecar index is written to dataPath+".idx.json"
+// when the Recorder is closed.
+func NewRecorder(conn k256.WSConn, dataPath string) (*Recorder, error) {
+	f, err := os.Create(dataPath)
+	if err != nil {
+		return nil, fmt.Errorf("create capture file: %w", err)
+	}
+	return &Recorder{WSConn: conn, data: f, enc: k256.NewEncoder(f), indexPath: dataPath + ".idx.json"}, nil
+}
+
+// ReadMessage reads the next frame from the wrapped connection, recording
+// it before returning it to the caller. Non-binary frames (text control
+// frames) pass through without being recorded.
+func (r *Recorder) ReadMessage() (int, []byte, error) {
+	msgType, data, err := r.WSConn.ReadMessage()
+	if err != nil || msgType != binaryMessage || len(data) == 0 {
+		return msgType, data, err
+	}
+
+	if recErr := r.record(data); recErr != nil {
+		return msgType, data, fmt.Errorf("record frame: %w", recErr)
+	}
+	return msgType, data, nil
+}
+
+// record appends frame (a k256 wire message: frame[0] is its MessageType,
+// frame[1:] its payload) to the capture as a k256.Encoder frame, and notes
+// its position and metadata in the index.
+func (r *Recorder) record(frame []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	offset, err := r.data.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+
+	msgType := k256.MessageType(frame[0])
+	payload := frame[1:]
+	if err := r.enc.Encode(msgType, payload); err != nil {
+		return err
+	}
+
+	entry := IndexEntry{
+		Offset:      offset,
+		Length:      uint32(len(payload)),
+		MessageType: frame[0],
+		TimestampMs: time.Now().UnixMilli(),
+	}
+	if msgType == k256.MessageTypePoolUpdate {
+		if update, err := k256.DecodePoolUpdate(payload); err == nil {
+			entry.Sequence = update.Sequence
+		}
+	}
+	r.index = append(r.index, entry)
+	return nil
+}
+
+// Close flushes the sidecar index, closes the capture file and closes the
+// wrapped connection.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	idx := r.index
+	r.mu.Unlock()
+
+	if err := r.writeIndex(idx); err != nil {
+		return err
+	}
+	if err := r.data.Close(); err != nil {
+		return err
+	}
+	return r.WSConn.Close()
+}
+
+func (r *Recorder) writeIndex(idx []IndexEntry) error {
+	f, err := os.Create(r.indexPath)
+	if err != nil {
+		return fmt.Errorf("create index file: %w", err)
+	}
+	defer f.Close()
+	return json.NewEncoder(f).Encode(idx)
+}
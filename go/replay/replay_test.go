@@ -0,0 +1,99 @@
+package replay
+
+import (
+	"encoding/binary"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	k256 "github.com/k256-xyz/k256-sdks/go"
+)
+
+// stubConn is a minimal k256.WSConn yielding a fixed sequence of binary
+// frames, then an error once exhausted.
+type stubConn struct {
+	frames [][]byte
+	pos    int
+}
+
+func (c *stubConn) ReadMessage() (int, []byte, error) {
+	if c.pos >= len(c.frames) {
+		return 0, nil, fmt.Errorf("stubConn: exhausted")
+	}
+	frame := c.frames[c.pos]
+	c.pos++
+	return binaryMessage, frame, nil
+}
+
+func (c *stubConn) WriteMessage(int, []byte) error { return nil }
+func (c *stubConn) Close() error                   { return nil }
+
+func heartbeatFrame(uptimeSeconds uint64) []byte {
+	frame := make([]byte, 1+36)
+	frame[0] = byte(k256.MessageTypeHeartbeat)
+	binary.LittleEndian.PutUint64(frame[1:], 1700000000000)
+	binary.LittleEndian.PutUint64(frame[9:], uptimeSeconds)
+	binary.LittleEndian.PutUint64(frame[17:], 0)
+	binary.LittleEndian.PutUint64(frame[25:], 0)
+	binary.LittleEndian.PutUint32(frame[33:], 0)
+	return frame
+}
+
+// TestRecordAndReplay captures a stub session with Recorder and verifies
+// FileDialer reproduces the same frames in order, decodable the same way a
+// live k256.WebSocketClient would decode them.
+func TestRecordAndReplay(t *testing.T) {
+	want := []uint64{10, 20, 30}
+	stub := &stubConn{frames: [][]byte{
+		heartbeatFrame(want[0]),
+		heartbeatFrame(want[1]),
+		heartbeatFrame(want[2]),
+	}}
+
+	dataPath := filepath.Join(t.TempDir(), "session.bin")
+	rec, err := NewRecorder(stub, dataPath)
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+
+	for range want {
+		if _, _, err := rec.ReadMessage(); err != nil {
+			t.Fatalf("ReadMessage: %v", err)
+		}
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	dialer := FileDialer{DataPath: dataPath}
+	conn, _, err := dialer.Dial("", nil)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+
+	for i, wantUptime := range want {
+		msgType, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("frame %d: ReadMessage: %v", i, err)
+		}
+		if msgType != binaryMessage {
+			t.Fatalf("frame %d: got ws message type %d, want %d", i, msgType, binaryMessage)
+		}
+
+		decoded, err := k256.DecodeMessage(k256.MessageType(data[0]), data[1:])
+		if err != nil {
+			t.Fatalf("frame %d: DecodeMessage: %v", i, err)
+		}
+		hb, ok := decoded.(*k256.Heartbeat)
+		if !ok {
+			t.Fatalf("frame %d: decoded %T, want *k256.Heartbeat", i, decoded)
+		}
+		if hb.UptimeSeconds != wantUptime {
+			t.Fatalf("frame %d: UptimeSeconds = %d, want %d", i, hb.UptimeSeconds, wantUptime)
+		}
+	}
+
+	if _, _, err := conn.ReadMessage(); err == nil {
+		t.Fatal("expected an error once the capture is exhausted")
+	}
+}
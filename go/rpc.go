@@ -0,0 +1,155 @@
+package k256
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gorilla/websocket"
+)
+
+// DefaultSendQueueSize is the default capacity of the outbound send queue
+// used by CallContext and the subscribe/unsubscribe control frames.
+const DefaultSendQueueSize = 500
+
+// rpcRequest is an id-correlated request frame sent to the gateway.
+type rpcRequest struct {
+	ID     string      `json:"id"`
+	Method string      `json:"method"`
+	Params interface{} `json:"params,omitempty"`
+}
+
+// rpcResponse is an id-correlated response frame received from the gateway.
+type rpcResponse struct {
+	ID     string          `json:"id"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *string         `json:"error,omitempty"`
+}
+
+// rpcState holds the request/response plumbing backing
+// WebSocketClient.CallContext: a monotonic id generator and the table of
+// calls awaiting a response.
+type rpcState struct {
+	mu      sync.Mutex
+	nextID  uint64
+	pending map[string]chan *rpcResponse
+}
+
+func (s *rpcState) register(id string) chan *rpcResponse {
+	ch := make(chan *rpcResponse, 1)
+	s.mu.Lock()
+	if s.pending == nil {
+		s.pending = make(map[string]chan *rpcResponse)
+	}
+	s.pending[id] = ch
+	s.mu.Unlock()
+	return ch
+}
+
+func (s *rpcState) forget(id string) {
+	s.mu.Lock()
+	delete(s.pending, id)
+	s.mu.Unlock()
+}
+
+func (s *rpcState) resolve(resp *rpcResponse) {
+	s.mu.Lock()
+	ch, ok := s.pending[resp.ID]
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	select {
+	case ch <- resp:
+	default:
+	}
+}
+
+// CallContext sends a correlated RPC request over the WebSocket and blocks
+// until a matching response arrives or ctx is done. It multiplexes calls
+// over the same connection used for subscriptions, so many callers can have
+// requests in flight at once.
+func (c *WebSocketClient) CallContext(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	id := fmt.Sprintf("%d", atomic.AddUint64(&c.rpc.nextID, 1))
+
+	data, err := json.Marshal(rpcRequest{ID: id, Method: method, Params: params})
+	if err != nil {
+		return nil, fmt.Errorf("marshal rpc request: %w", err)
+	}
+
+	resultCh := c.rpc.register(id)
+	defer c.rpc.forget(id)
+
+	if err := c.enqueueSend(ctx, websocket.TextMessage, data); err != nil {
+		return nil, err
+	}
+
+	select {
+	case resp := <-resultCh:
+		if resp.Error != nil {
+			return nil, fmt.Errorf("rpc error: %s", *resp.Error)
+		}
+		return resp.Result, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// sendFrame is a queued outbound message awaiting a write by writePump.
+type sendFrame struct {
+	msgType int
+	data    []byte
+}
+
+// enqueueSend hands data to the outbound send queue, which a dedicated writer
+// goroutine drains, so a slow or blocked peer can't stall the reader
+// goroutine. It returns ctx.Err() if the queue is full and ctx is done first.
+func (c *WebSocketClient) enqueueSend(ctx context.Context, msgType int, data []byte) error {
+	select {
+	case c.sendQueue <- sendFrame{msgType: msgType, data: data}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *WebSocketClient) handleRPCResponse(data []byte) bool {
+	var resp rpcResponse
+	if err := json.Unmarshal(data, &resp); err != nil || resp.ID == "" {
+		return false
+	}
+	c.rpc.resolve(&resp)
+	return true
+}
+
+// writePump drains the outbound send queue for the lifetime of the client,
+// writing each frame to whichever connection is current. Serializing writes
+// through a single goroutine keeps CallContext callers and the
+// subscribe/unsubscribe control frames from writing to the WebSocket
+// concurrently with each other or with the reader goroutine. Frames queued
+// while disconnected are dropped; the reconnect logic replays every active
+// subscription, and CallContext callers time out via their own ctx.
+func (c *WebSocketClient) writePump() {
+	for {
+		select {
+		case frame := <-c.sendQueue:
+			c.mu.RLock()
+			conn := c.conn
+			c.mu.RUnlock()
+			if conn == nil {
+				log.Printf("writePump: dropping frame, not connected")
+				continue
+			}
+
+			if err := conn.WriteMessage(frame.msgType, frame.data); err != nil {
+				log.Printf("writePump error: %v", err)
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package k256
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// fakeRPCConn is a minimal WSConn for exercising CallContext and writePump:
+// WriteMessage records every frame written to it, and ReadMessage returns
+// messages pushed via pushResponse, blocking until one arrives or the conn
+// is closed.
+type fakeRPCConn struct {
+	mu      sync.Mutex
+	written [][]byte
+	inbound chan []byte
+	closed  chan struct{}
+}
+
+func newFakeRPCConn() *fakeRPCConn {
+	return &fakeRPCConn{inbound: make(chan []byte, 16), closed: make(chan struct{})}
+}
+
+func (c *fakeRPCConn) ReadMessage() (int, []byte, error) {
+	select {
+	case data := <-c.inbound:
+		return websocket.TextMessage, data, nil
+	case <-c.closed:
+		return 0, nil, fmt.Errorf("fakeRPCConn: closed")
+	}
+}
+
+func (c *fakeRPCConn) WriteMessage(messageType int, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.written = append(c.written, append([]byte(nil), data...))
+	return nil
+}
+
+func (c *fakeRPCConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	select {
+	case <-c.closed:
+	default:
+		close(c.closed)
+	}
+	return nil
+}
+
+func (c *fakeRPCConn) writes() [][]byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([][]byte(nil), c.written...)
+}
+
+func (c *fakeRPCConn) pushResponse(resp rpcResponse) {
+	data, _ := json.Marshal(resp)
+	c.inbound <- data
+}
+
+// fakeRPCDialer hands out a fresh fakeRPCConn per Dial call, in order, so a
+// test can drive a WebSocketClient through one or more connect cycles and
+// inspect each connection it produced.
+type fakeRPCDialer struct {
+	mu    sync.Mutex
+	conns []*fakeRPCConn
+}
+
+func (d *fakeRPCDialer) Dial(urlStr string, requestHeader http.Header) (WSConn, *http.Response, error) {
+	conn := newFakeRPCConn()
+	d.mu.Lock()
+	d.conns = append(d.conns, conn)
+	d.mu.Unlock()
+	return conn, nil, nil
+}
+
+func (d *fakeRPCDialer) conn(n int) *fakeRPCConn {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if n <= len(d.conns) {
+		return d.conns[n-1]
+	}
+	return nil
+}
+
+func (d *fakeRPCDialer) waitForConn(t *testing.T, n int) *fakeRPCConn {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if conn := d.conn(n); conn != nil {
+			return conn
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("dialer never produced connection #%d", n)
+	return nil
+}
+
+func newTestClient(dialer Dialer) *WebSocketClient {
+	return NewWebSocket(Config{
+		Endpoint:  "ws://test",
+		Reconnect: false,
+		Dialer:    dialer,
+	})
+}
+
+func TestCallContextHappyPath(t *testing.T) {
+	dialer := &fakeRPCDialer{}
+	c := newTestClient(dialer)
+	go c.Connect()
+	defer c.Close()
+	conn := dialer.waitForConn(t, 1)
+
+	resultCh := make(chan json.RawMessage, 1)
+	errCh := make(chan error, 1)
+	go func() {
+		result, err := c.CallContext(context.Background(), "getSlot", nil)
+		if err != nil {
+			errCh <- err
+			return
+		}
+		resultCh <- result
+	}()
+
+	var req rpcRequest
+	deadline := time.Now().Add(time.Second)
+	for {
+		if writes := conn.writes(); len(writes) > 0 {
+			if err := json.Unmarshal(writes[0], &req); err != nil {
+				t.Fatalf("unmarshal request: %v", err)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("CallContext never wrote a request frame")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if req.Method != "getSlot" {
+		t.Fatalf("Method = %q, want getSlot", req.Method)
+	}
+
+	conn.pushResponse(rpcResponse{ID: req.ID, Result: json.RawMessage(`12345`)})
+
+	select {
+	case result := <-resultCh:
+		if string(result) != "12345" {
+			t.Fatalf("Result = %s, want 12345", result)
+		}
+	case err := <-errCh:
+		t.Fatalf("CallContext returned an error: %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("CallContext did not return after the response arrived")
+	}
+}
+
+func TestCallContextTimeout(t *testing.T) {
+	dialer := &fakeRPCDialer{}
+	c := newTestClient(dialer)
+	go c.Connect()
+	defer c.Close()
+	dialer.waitForConn(t, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := c.CallContext(ctx, "getSlot", nil); err != context.DeadlineExceeded {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+// TestReconnectRecreatesDoneChannel guards against a prior bug: Close closes
+// c.done to stop writePump, but a subsequent Connect started a new writePump
+// goroutine selecting on that same, already-closed channel, so it returned
+// immediately instead of draining the send queue - silently breaking
+// CallContext and subscribe/unsubscribe on every reconnect.
+func TestReconnectRecreatesDoneChannel(t *testing.T) {
+	dialer := &fakeRPCDialer{}
+	c := newTestClient(dialer)
+
+	done1 := make(chan error, 1)
+	go func() { done1 <- c.Connect() }()
+	dialer.waitForConn(t, 1)
+
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := <-done1; err == nil {
+		t.Fatal("expected Connect to return an error once the connection is closed")
+	}
+
+	done2 := make(chan error, 1)
+	go func() { done2 <- c.Connect() }()
+	defer c.Close()
+	conn2 := dialer.waitForConn(t, 2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := c.enqueueSend(ctx, websocket.TextMessage, []byte("hello")); err != nil {
+		t.Fatalf("enqueueSend: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for len(conn2.writes()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	writes := conn2.writes()
+	if len(writes) != 1 || string(writes[0]) != "hello" {
+		t.Fatalf("writePump did not deliver the frame to the reconnected conn, got %v", writes)
+	}
+}
@@ -0,0 +1,348 @@
+package k256
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+)
+
+// maxGossipDiffGap is the longest acceptable silence between gossip diffs
+// before LeaderState assumes a message was missed and resubscribes for a
+// fresh snapshot.
+const maxGossipDiffGap = 30 * time.Second
+
+// skipWindowRetention bounds how long skip-event samples are kept in memory,
+// independent of the window callers pass to SkipRate.
+const skipWindowRetention = 30 * time.Minute
+
+// Change is delivered to a Watch channel when the keyed state changes.
+type Change struct {
+	// Key identifies what changed, e.g. a validator identity or "slot".
+	Key string
+	// Kind is the envelope kind that produced the change.
+	Kind MessageKind
+}
+
+type skipSample struct {
+	at       time.Time
+	assigned uint32
+	produced uint32
+}
+
+// LeaderState subscribes to a leader-schedule WebSocket and maintains a
+// thread-safe, reconciled view of the gossip table, leader schedule, and
+// current slot, so callers don't have to replay snapshot+diff envelopes
+// themselves.
+type LeaderState struct {
+	mu sync.RWMutex
+
+	client *LeaderWebSocketClient
+
+	currentSlot    uint64
+	epoch          uint64
+	slotsInEpoch   uint64
+	epochStartSlot uint64
+	slotIndex      map[uint64]string // slot-in-epoch index -> identity
+
+	peers               map[string]*GossipPeer
+	lastGossipTimestamp uint64
+
+	skipEvents map[string][]skipSample
+
+	watchers map[string][]chan Change
+}
+
+// NewLeaderState creates a LeaderState that owns a LeaderWebSocketClient built
+// from config. Call Connect to start streaming.
+func NewLeaderState(config LeaderConfig) *LeaderState {
+	s := &LeaderState{
+		slotIndex:  make(map[uint64]string),
+		peers:      make(map[string]*GossipPeer),
+		skipEvents: make(map[string][]skipSample),
+		watchers:   make(map[string][]chan Change),
+	}
+	s.client = NewLeaderClient(config, s.handleMessage)
+	return s
+}
+
+// Connect establishes the underlying WebSocket connection and begins
+// populating state.
+func (s *LeaderState) Connect() error {
+	return s.client.Connect()
+}
+
+// Close disconnects the underlying WebSocket client.
+func (s *LeaderState) Close() {
+	s.client.Close()
+}
+
+func (s *LeaderState) handleMessage(msg LeaderMessage) {
+	switch msg.Type {
+	case MsgGossipSnapshot:
+		var data GossipSnapshotData
+		if err := json.Unmarshal(msg.Data, &data); err != nil {
+			return
+		}
+		s.applyGossipSnapshot(data)
+
+	case MsgGossipDiff:
+		var data GossipDiffData
+		if err := json.Unmarshal(msg.Data, &data); err != nil {
+			return
+		}
+		if s.gossipGapDetected(data) {
+			log.Printf("[LeaderState] gossip diff gap detected, resubscribing for a fresh snapshot")
+			s.forceResubscribe()
+			return
+		}
+		s.applyGossipDiff(data)
+
+	case MsgSlotUpdate:
+		var data SlotUpdateData
+		if err := json.Unmarshal(msg.Data, &data); err != nil {
+			return
+		}
+		s.applySlotUpdate(data)
+
+	case MsgLeaderHeartbeat:
+		var data LeaderHeartbeatData
+		if err := json.Unmarshal(msg.Data, &data); err != nil {
+			return
+		}
+		s.applyHeartbeat(data)
+
+	case MsgLeaderSchedule:
+		var data LeaderScheduleData
+		if err := json.Unmarshal(msg.Data, &data); err != nil {
+			return
+		}
+		s.applyLeaderSchedule(data)
+
+	case MsgSkipEvent:
+		var data SkipEventData
+		if err := json.Unmarshal(msg.Data, &data); err != nil {
+			return
+		}
+		s.applySkipEvent(data)
+	}
+}
+
+func (s *LeaderState) gossipGapDetected(data GossipDiffData) bool {
+	s.mu.RLock()
+	last := s.lastGossipTimestamp
+	s.mu.RUnlock()
+
+	if last == 0 || data.TimestampMs <= last {
+		return false
+	}
+	return time.Duration(data.TimestampMs-last)*time.Millisecond > maxGossipDiffGap
+}
+
+// forceResubscribe drops the current gossip table and reconnects, which
+// re-runs the subscribe handshake and so obtains a fresh gossip_snapshot.
+// handleMessage runs inline on the client's own readLoop goroutine, so this
+// uses Reconnect (swap the connection in place) rather than Close+Connect
+// (which would start a second readLoop goroutine racing the current one for
+// ReadMessage on the new connection).
+func (s *LeaderState) forceResubscribe() {
+	s.mu.Lock()
+	s.peers = make(map[string]*GossipPeer)
+	s.lastGossipTimestamp = 0
+	s.mu.Unlock()
+
+	if err := s.client.Reconnect(); err != nil {
+		log.Printf("[LeaderState] resubscribe failed: %v", err)
+	}
+}
+
+func (s *LeaderState) applyGossipSnapshot(data GossipSnapshotData) {
+	peers := make(map[string]*GossipPeer, len(data.Peers))
+	for i := range data.Peers {
+		peer := data.Peers[i]
+		peers[peer.Identity] = &peer
+	}
+
+	s.mu.Lock()
+	s.peers = peers
+	s.lastGossipTimestamp = data.Timestamp
+	s.mu.Unlock()
+
+	s.notify("gossip", KindSnapshot)
+}
+
+func (s *LeaderState) applyGossipDiff(data GossipDiffData) {
+	s.mu.Lock()
+	for i := range data.Added {
+		peer := data.Added[i]
+		s.peers[peer.Identity] = &peer
+	}
+	for i := range data.Updated {
+		peer := data.Updated[i]
+		s.peers[peer.Identity] = &peer
+	}
+	for _, identity := range data.Removed {
+		delete(s.peers, identity)
+	}
+	s.lastGossipTimestamp = data.TimestampMs
+	s.mu.Unlock()
+
+	for _, peer := range data.Added {
+		s.notify(peer.Identity, KindDiff)
+	}
+	for _, peer := range data.Updated {
+		s.notify(peer.Identity, KindDiff)
+	}
+	for _, identity := range data.Removed {
+		s.notify(identity, KindDiff)
+	}
+}
+
+func (s *LeaderState) applySlotUpdate(data SlotUpdateData) {
+	s.mu.Lock()
+	s.currentSlot = data.Slot
+	s.mu.Unlock()
+
+	s.notify("slot", KindEvent)
+}
+
+func (s *LeaderState) applyHeartbeat(data LeaderHeartbeatData) {
+	s.mu.Lock()
+	s.currentSlot = data.CurrentSlot
+	s.mu.Unlock()
+
+	s.notify("slot", KindEvent)
+}
+
+func (s *LeaderState) applyLeaderSchedule(data LeaderScheduleData) {
+	slotIndex := make(map[uint64]string, data.SlotsInEpoch)
+	for _, v := range data.Schedule {
+		for _, idx := range v.SlotIndices {
+			slotIndex[uint64(idx)] = v.Identity
+		}
+	}
+
+	s.mu.Lock()
+	s.epoch = data.Epoch
+	s.slotsInEpoch = data.SlotsInEpoch
+	s.slotIndex = slotIndex
+	if s.slotsInEpoch > 0 {
+		s.epochStartSlot = s.currentSlot - (s.currentSlot % s.slotsInEpoch)
+	}
+	s.mu.Unlock()
+
+	s.notify("schedule", KindSnapshot)
+}
+
+func (s *LeaderState) applySkipEvent(data SkipEventData) {
+	now := time.Now()
+	cutoff := now.Add(-skipWindowRetention)
+
+	s.mu.Lock()
+	samples := append(s.skipEvents[data.Leader], skipSample{at: now, assigned: data.Assigned, produced: data.Produced})
+	trimmed := samples[:0]
+	for _, sample := range samples {
+		if sample.at.After(cutoff) {
+			trimmed = append(trimmed, sample)
+		}
+	}
+	s.skipEvents[data.Leader] = trimmed
+	s.mu.Unlock()
+
+	s.notify(data.Leader, KindEvent)
+}
+
+// LeaderAt returns the validator identity scheduled to lead the given slot, if
+// the current leader schedule covers it.
+func (s *LeaderState) LeaderAt(slot uint64) (identity string, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.slotsInEpoch == 0 || slot < s.epochStartSlot {
+		return "", false
+	}
+	identity, ok = s.slotIndex[slot-s.epochStartSlot]
+	return identity, ok
+}
+
+// Peer returns the gossip-advertised info for a validator identity, if known.
+func (s *LeaderState) Peer(identity string) (*GossipPeer, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	peer, ok := s.peers[identity]
+	return peer, ok
+}
+
+// LeadersAhead returns the gossip entries for the next n scheduled leaders,
+// starting with the current slot. Slots without a known schedule entry or
+// gossip peer are omitted, so the result may be shorter than n.
+func (s *LeaderState) LeadersAhead(n int) []GossipPeer {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	peers := make([]GossipPeer, 0, n)
+	for slot := s.currentSlot; len(peers) < n; slot++ {
+		identity, ok := s.slotIndex[slot-s.epochStartSlot]
+		if !ok {
+			break
+		}
+		if peer, ok := s.peers[identity]; ok {
+			peers = append(peers, *peer)
+		}
+	}
+	return peers
+}
+
+// SkipRate returns the fraction of assigned slots a validator failed to
+// produce within the trailing window, based on skip_event samples. It returns
+// 0 if no samples fall within the window.
+func (s *LeaderState) SkipRate(identity string, window time.Duration) float32 {
+	cutoff := time.Now().Add(-window)
+
+	s.mu.RLock()
+	samples := s.skipEvents[identity]
+	s.mu.RUnlock()
+
+	var assigned, produced uint32
+	for _, sample := range samples {
+		if sample.at.Before(cutoff) {
+			continue
+		}
+		assigned += sample.assigned
+		produced += sample.produced
+	}
+	if assigned == 0 {
+		return 0
+	}
+	return float32(assigned-produced) / float32(assigned)
+}
+
+// Watch returns a channel that receives a Change whenever the given key
+// updates. Recognized keys are "slot", "gossip", "schedule", and validator
+// identities (for both gossip and skip-event updates). The channel is
+// buffered; sends are non-blocking, so a slow reader misses updates rather
+// than stalling state application.
+func (s *LeaderState) Watch(key string) <-chan Change {
+	ch := make(chan Change, 8)
+
+	s.mu.Lock()
+	s.watchers[key] = append(s.watchers[key], ch)
+	s.mu.Unlock()
+
+	return ch
+}
+
+func (s *LeaderState) notify(key string, kind MessageKind) {
+	s.mu.RLock()
+	watchers := s.watchers[key]
+	chans := make([]chan Change, len(watchers))
+	copy(chans, watchers)
+	s.mu.RUnlock()
+
+	for _, ch := range chans {
+		select {
+		case ch <- Change{Key: key, Kind: kind}:
+		default:
+		}
+	}
+}
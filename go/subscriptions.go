@@ -0,0 +1,130 @@
+package k256
+
+import "sync"
+
+// SubID identifies a single subscription registered through SubscribePool,
+// SubscribePair, SubscribeQuote, SubscribeFees, SubscribeBlockhash or
+// SubscribeAllPools, for later removal via Unsubscribe. The zero value is
+// never issued and can be used as an "unset" sentinel.
+type SubID uint64
+
+const (
+	allPoolsKey  = "pools"
+	feesKey      = "fees"
+	blockhashKey = "blockhash"
+)
+
+func poolKey(addr string) string { return "pool:" + addr }
+
+// pairKey is order-independent: SubscribePair(a, b) and SubscribePair(b, a)
+// resolve to the same server-side subscription.
+func pairKey(mintA, mintB string) string {
+	if mintA > mintB {
+		mintA, mintB = mintB, mintA
+	}
+	return "pair:" + mintA + ":" + mintB
+}
+
+// quoteKey is direction-sensitive: a quote for A->B is a different stream
+// than B->A.
+func quoteKey(inputMint, outputMint string) string {
+	return "quotes:" + inputMint + ":" + outputMint
+}
+
+// subscription is one entry tracked by subscriptionManager: the wire frame
+// needed to (re)establish it with the gateway, plus the typed callback to
+// invoke on a matching update.
+type subscription struct {
+	id       SubID
+	key      string
+	frame    SubscribeRequest
+	callback interface{}
+}
+
+// subscriptionManager tracks every active keyed subscription (pool:<addr>,
+// pair:<mintA>:<mintB>, quotes:<mintA>:<mintB>, fees, blockhash, pools),
+// modeled after Blockbook's addressSubscriptions/newBlockSubscriptions maps.
+// Several callers can hold independent subscriptions under the same key
+// without clobbering each other, unlike the single lastSubscription field it
+// replaces. On reconnect every entry is replayed as its own subscribe frame
+// instead of one combined request.
+type subscriptionManager struct {
+	mu     sync.Mutex
+	nextID SubID
+	byKey  map[string]map[SubID]*subscription
+}
+
+func newSubscriptionManager() *subscriptionManager {
+	return &subscriptionManager{byKey: make(map[string]map[SubID]*subscription)}
+}
+
+// add registers a new subscription under key. first reports whether this is
+// the first live subscription for key, i.e. whether the caller needs to send
+// frame to the gateway (later callers under the same key piggyback on the
+// subscription already established there).
+func (m *subscriptionManager) add(key string, frame SubscribeRequest, callback interface{}) (sub *subscription, first bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextID++
+	sub = &subscription{id: m.nextID, key: key, frame: frame, callback: callback}
+
+	bucket, ok := m.byKey[key]
+	if !ok {
+		bucket = make(map[SubID]*subscription)
+		m.byKey[key] = bucket
+	}
+	bucket[sub.id] = sub
+	return sub, !ok
+}
+
+// remove deletes id and reports the frame to send as an unsubscribe, if it
+// was the last subscription sharing its key.
+func (m *subscriptionManager) remove(id SubID) (frame SubscribeRequest, last bool, found bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, bucket := range m.byKey {
+		sub, ok := bucket[id]
+		if !ok {
+			continue
+		}
+		delete(bucket, id)
+		if len(bucket) == 0 {
+			delete(m.byKey, key)
+			return sub.frame, true, true
+		}
+		return sub.frame, false, true
+	}
+	return SubscribeRequest{}, false, false
+}
+
+// snapshot returns every active subscription, for replay after a reconnect.
+func (m *subscriptionManager) snapshot() []*subscription {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var all []*subscription
+	for _, bucket := range m.byKey {
+		for _, sub := range bucket {
+			all = append(all, sub)
+		}
+	}
+	return all
+}
+
+// callbacksForKey returns every callback registered under key, for dispatch.
+func (m *subscriptionManager) callbacksForKey(key string) []interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	bucket, ok := m.byKey[key]
+	if !ok {
+		return nil
+	}
+	cbs := make([]interface{}, 0, len(bucket))
+	for _, sub := range bucket {
+		cbs = append(cbs, sub.callback)
+	}
+	return cbs
+}
@@ -0,0 +1,75 @@
+// Package tpu turns K256's leader-schedule WebSocket stream into an actionable
+// transaction send path: it keeps a live routing table of upcoming leaders and
+// their QUIC TPU sockets, and forwards signed transactions directly to them.
+package tpu
+
+import (
+	"fmt"
+
+	k256 "github.com/k256-xyz/k256-sdks/go"
+)
+
+// Router forwards signed transactions to the current and upcoming leaders'
+// gossip-advertised QUIC TPU sockets. It reads the routing table from a
+// *k256.LeaderState rather than deriving its own copy of the gossip/leader
+// schedule reconciliation, so there's exactly one reconciled view of that
+// stream in the tree.
+type Router struct {
+	state *k256.LeaderState
+
+	metrics Metrics
+}
+
+// NewRouter returns a Router backed by state, which the caller is
+// responsible for connecting (state.Connect) so it can begin populating the
+// routing table Router reads from.
+func NewRouter(state *k256.LeaderState) *Router {
+	return &Router{state: state}
+}
+
+// LeaderAt returns the validator identity scheduled to lead the given slot,
+// if the current leader schedule covers it.
+func (r *Router) LeaderAt(slot uint64) (identity string, ok bool) {
+	return r.state.LeaderAt(slot)
+}
+
+// Peer returns the gossip-advertised info for a validator identity, if known.
+func (r *Router) Peer(identity string) (*k256.GossipPeer, bool) {
+	return r.state.Peer(identity)
+}
+
+// LeadersAhead returns the identities scheduled to lead the next n slots,
+// starting with the current slot. Slots the schedule doesn't cover yet, or
+// without a known gossip peer, are omitted, so the result may be shorter
+// than n.
+func (r *Router) LeadersAhead(n int) []string {
+	peers := r.state.LeadersAhead(n)
+	identities := make([]string, len(peers))
+	for i, peer := range peers {
+		identities[i] = peer.Identity
+	}
+	return identities
+}
+
+// Metrics returns a snapshot of the Router's send counters.
+func (r *Router) Metrics() Metrics {
+	return r.metrics.snapshot()
+}
+
+func (r *Router) tpuSocket(identity string, preferForwards bool) (string, error) {
+	peer, ok := r.Peer(identity)
+	if !ok {
+		return "", fmt.Errorf("tpu: no gossip entry for leader %s", identity)
+	}
+	if peer.IsDelinquent {
+		return "", fmt.Errorf("tpu: leader %s is delinquent", identity)
+	}
+
+	if preferForwards && peer.TpuForwardsQuic != nil {
+		return *peer.TpuForwardsQuic, nil
+	}
+	if peer.TpuQuic != nil {
+		return *peer.TpuQuic, nil
+	}
+	return "", fmt.Errorf("tpu: leader %s has no QUIC TPU socket", identity)
+}
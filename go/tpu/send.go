@@ -0,0 +1,167 @@
+package tpu
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"sync"
+
+	"github.com/quic-go/quic-go"
+)
+
+// SendOptions configures how SendTransaction fans a transaction out to leaders.
+type SendOptions struct {
+	// LeadersAhead is how many upcoming leader slots to send to, starting with
+	// the current slot.
+	LeadersAhead int
+	// RetriesPerSlot is how many additional QUIC send attempts to make against a
+	// single leader before giving up on it.
+	RetriesPerSlot int
+	// PreferForwards routes to a leader's TPU-forwards socket instead of its
+	// primary TPU, which is the polite path for anything but the current slot.
+	PreferForwards bool
+}
+
+// DefaultSendOptions returns reasonable defaults for SendTransaction.
+func DefaultSendOptions() SendOptions {
+	return SendOptions{
+		LeadersAhead:   2,
+		RetriesPerSlot: 1,
+		PreferForwards: true,
+	}
+}
+
+// quicTLSConfig is used for TPU QUIC connections. Validators present
+// self-signed certificates, so verification is skipped like other Solana TPU
+// QUIC clients; the transaction itself is authenticated by its signature.
+var quicTLSConfig = &tls.Config{
+	InsecureSkipVerify: true,
+	NextProtos:         []string{"solana-tpu"},
+}
+
+// SendTransaction forwards a signed transaction directly to the current and
+// upcoming leaders' TPU QUIC sockets, skipping delinquent validators and
+// leaders missing from the gossip table. It returns nil if at least one leader
+// accepted the transaction.
+func (r *Router) SendTransaction(ctx context.Context, tx []byte, opts SendOptions) error {
+	leaders := r.LeadersAhead(opts.LeadersAhead)
+	if len(leaders) == 0 {
+		return fmt.Errorf("tpu: no upcoming leaders known, is the leader schedule subscription live?")
+	}
+
+	var (
+		sent    int
+		lastErr error
+	)
+	for i, identity := range leaders {
+		preferForwards := opts.PreferForwards && i > 0
+		addr, err := r.tpuSocket(identity, preferForwards)
+		if err != nil {
+			r.metrics.recordDropped()
+			lastErr = err
+			continue
+		}
+
+		if err := sendWithRetry(ctx, addr, tx, opts.RetriesPerSlot); err != nil {
+			r.metrics.recordFailure(identity)
+			lastErr = err
+			continue
+		}
+		r.metrics.recordSuccess(identity)
+		sent++
+	}
+
+	if sent == 0 {
+		return fmt.Errorf("tpu: transaction not accepted by any leader: %w", lastErr)
+	}
+	return nil
+}
+
+func sendWithRetry(ctx context.Context, addr string, tx []byte, retries int) error {
+	var lastErr error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if err := sendOnce(ctx, addr, tx); err != nil {
+			lastErr = err
+			continue
+		}
+		return nil
+	}
+	return lastErr
+}
+
+func sendOnce(ctx context.Context, addr string, tx []byte) error {
+	conn, err := quic.DialAddr(ctx, addr, quicTLSConfig, nil)
+	if err != nil {
+		return fmt.Errorf("quic dial %s: %w", addr, err)
+	}
+	defer conn.CloseWithError(0, "")
+
+	stream, err := conn.OpenUniStreamSync(ctx)
+	if err != nil {
+		return fmt.Errorf("open uni stream to %s: %w", addr, err)
+	}
+	defer stream.Close()
+
+	if _, err := stream.Write(tx); err != nil {
+		return fmt.Errorf("write transaction to %s: %w", addr, err)
+	}
+	return nil
+}
+
+// Metrics tracks SendTransaction outcomes per leader.
+type Metrics struct {
+	mu sync.Mutex
+
+	// SuccessByLeader counts accepted sends, keyed by validator identity.
+	SuccessByLeader map[string]uint64
+	// FailureByLeader counts QUIC send failures, keyed by validator identity.
+	FailureByLeader map[string]uint64
+	// DroppedMissingGossip counts leaders skipped because they were delinquent,
+	// absent from the gossip table, or advertised no QUIC TPU socket, mirroring
+	// k256.RoutingHealthData.LeadersWithoutTpuQuic.
+	DroppedMissingGossip uint64
+}
+
+func (m *Metrics) recordSuccess(identity string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.SuccessByLeader == nil {
+		m.SuccessByLeader = make(map[string]uint64)
+	}
+	m.SuccessByLeader[identity]++
+}
+
+func (m *Metrics) recordFailure(identity string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.FailureByLeader == nil {
+		m.FailureByLeader = make(map[string]uint64)
+	}
+	m.FailureByLeader[identity]++
+}
+
+func (m *Metrics) recordDropped() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.DroppedMissingGossip++
+}
+
+func (m *Metrics) snapshot() Metrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	success := make(map[string]uint64, len(m.SuccessByLeader))
+	for k, v := range m.SuccessByLeader {
+		success[k] = v
+	}
+	failure := make(map[string]uint64, len(m.FailureByLeader))
+	for k, v := range m.FailureByLeader {
+		failure[k] = v
+	}
+
+	return Metrics{
+		SuccessByLeader:      success,
+		FailureByLeader:      failure,
+		DroppedMissingGossip: m.DroppedMissingGossip,
+	}
+}